@@ -10,7 +10,16 @@ import (
 
 	"github.com/K1flar/crawlers/internal/actions/consume_tasks_to_process"
 	produce_tasks_to_process_action "github.com/K1flar/crawlers/internal/actions/produce_tasks_to_process"
+	purge_deleted_tasks_action "github.com/K1flar/crawlers/internal/actions/purge_deleted_tasks"
+	reindex_source_contents_action "github.com/K1flar/crawlers/internal/actions/reindex_source_contents"
+	rekey_sources_action "github.com/K1flar/crawlers/internal/actions/rekey_sources"
+	"github.com/K1flar/crawlers/internal/gates"
+	"github.com/K1flar/crawlers/internal/gates/http_scraper"
+	"github.com/K1flar/crawlers/internal/gates/http_summarizer"
+	"github.com/K1flar/crawlers/internal/gates/page_fetcher"
+	"github.com/K1flar/crawlers/internal/gates/robots_txt"
 	"github.com/K1flar/crawlers/internal/gates/searx"
+	"github.com/K1flar/crawlers/internal/gates/text_rank"
 	"github.com/K1flar/crawlers/internal/gates/web_scraper"
 	"github.com/K1flar/crawlers/internal/http_client"
 	"github.com/K1flar/crawlers/internal/message_broker/kafka"
@@ -18,11 +27,17 @@ import (
 	"github.com/K1flar/crawlers/internal/services/crawler"
 	"github.com/K1flar/crawlers/internal/services/launcher"
 	"github.com/K1flar/crawlers/internal/storage/launches"
+	"github.com/K1flar/crawlers/internal/storage/source_aliases"
+	"github.com/K1flar/crawlers/internal/storage/source_contents"
+	"github.com/K1flar/crawlers/internal/storage/source_links"
 	"github.com/K1flar/crawlers/internal/storage/sources"
 	"github.com/K1flar/crawlers/internal/storage/task_sources"
 	"github.com/K1flar/crawlers/internal/storage/tasks"
 	"github.com/K1flar/crawlers/internal/stories/process_task"
 	"github.com/K1flar/crawlers/internal/stories/produce_tasks_to_process"
+	"github.com/K1flar/crawlers/internal/stories/purge_deleted_tasks"
+	"github.com/K1flar/crawlers/internal/stories/reindex_source_contents"
+	"github.com/K1flar/crawlers/internal/stories/rekey_sources"
 	"github.com/K1flar/crawlers/internal/worker"
 	"github.com/jmoiron/sqlx"
 	dotenv "github.com/joho/godotenv"
@@ -43,8 +58,29 @@ const (
 	maxCountCrawlers    = "MAX_COUNT_CRAWLERS"
 	defaulCountCrawlers = 10
 
+	// Период, с которым планировщик ищет задачи с наступившим временем запуска
 	cronTasksToProcessPeriod    = "CRON_TASKS_TO_PROCESS_PRODUCER_PERIOD"
-	defaultTasksToProcessPeriod = time.Hour * 24
+	defaultTasksToProcessPeriod = time.Minute
+
+	cronPurgeDeletedTasksPeriod    = "CRON_PURGE_DELETED_TASKS_PERIOD"
+	defaultPurgeDeletedTasksPeriod = time.Hour * 24
+
+	// Срок, в течение которого удаленная задача хранится в базе
+	deletedTasksRetention        = "DELETED_TASKS_RETENTION"
+	defaultDeletedTasksRetention = time.Hour * 24 * 30
+
+	webScraperMode        = "WEB_SCRAPER_MODE"
+	defaultWebScraperMode = page_fetcher.ModeBrowser
+
+	crawlerUserAgent        = "CRAWLER_USER_AGENT"
+	defaultCrawlerUserAgent = "crawlers-bot/1.0"
+
+	// Если адрес сервиса пересказа не задан, используется встроенный TextRank
+	summarizerHost = "SUMMARIZER_HOST"
+	summarizerPort = "SUMMARIZER_PORT"
+
+	summarySentences        = "SUMMARY_SENTENCES"
+	defaultSummarySentences = 3
 )
 
 type cmd func(ctx context.Context)
@@ -84,6 +120,39 @@ func main() {
 		tasksToProcessPeriod = defaultTasksToProcessPeriod
 	}
 
+	purgeDeletedTasksPeriod, err := time.ParseDuration(os.Getenv(cronPurgeDeletedTasksPeriod))
+	if err != nil {
+		log.Warn(fmt.Sprintf("failed to parse cron purge deleted tasks period: [%s]: %s", os.Getenv(cronPurgeDeletedTasksPeriod), err))
+
+		purgeDeletedTasksPeriod = defaultPurgeDeletedTasksPeriod
+	}
+
+	retention, err := time.ParseDuration(os.Getenv(deletedTasksRetention))
+	if err != nil {
+		log.Warn(fmt.Sprintf("failed to parse deleted tasks retention: [%s]: %s", os.Getenv(deletedTasksRetention), err))
+
+		retention = defaultDeletedTasksRetention
+	}
+
+	scraperMode, err := page_fetcher.ParseMode(os.Getenv(webScraperMode))
+	if err != nil {
+		log.Warn(fmt.Sprintf("failed to parse web scraper mode: %s", err))
+
+		scraperMode = defaultWebScraperMode
+	}
+
+	userAgent := os.Getenv(crawlerUserAgent)
+	if userAgent == "" {
+		userAgent = defaultCrawlerUserAgent
+	}
+
+	summarySentencesInt, err := strconv.Atoi(os.Getenv(summarySentences))
+	if err != nil || summarySentencesInt <= 0 {
+		log.Warn(fmt.Sprintf("failed to parse summary sentences: [%s]", os.Getenv(summarySentences)))
+
+		summarySentencesInt = defaultSummarySentences
+	}
+
 	db, err := sqlx.Connect("postgres", os.Getenv(postgresDSN))
 	if err != nil {
 		log.Error(err.Error())
@@ -112,26 +181,50 @@ func main() {
 	taskSourcesStorage := task_sources.NewStorage(db)
 	sourcesStorage := sources.NewStorage(db)
 	launchesStorage := launches.NewStorage(db)
+	sourceContentsStorage := source_contents.NewStorage(db)
+	sourceAliasesStorage := source_aliases.NewStorage(db)
+	sourceLinksStorage := source_links.NewStorage(db)
 
 	// Gates
 	sxGate := searx.NewGate(log, searxClient)
-	webScraperGate := web_scraper.NewGate()
+	webScraperGate := page_fetcher.NewGate(log, http_scraper.NewGate(userAgent), web_scraper.NewGate(), scraperMode)
+	robotsTxtGate := robots_txt.NewGate(userAgent)
+
+	var summarizerGate gates.Summarizer = text_rank.NewGate(summarySentencesInt)
+	if os.Getenv(summarizerHost) != "" {
+		summarizerClient := http_client.New(
+			http_client.WithBaseURL(os.Getenv(summarizerHost) + ":" + os.Getenv(summarizerPort)),
+		)
+
+		summarizerGate = http_summarizer.NewGate(log, summarizerClient)
+	}
 
 	// Services
-	crawler := crawler.New(log, sxGate, webScraperGate)
-	launcher := launcher.NewService(log, launchesStorage, taskSourcesStorage, sourcesStorage)
+	crawler := crawler.New(log, sxGate, webScraperGate, robotsTxtGate)
+	launcher := launcher.NewService(log, launchesStorage, taskSourcesStorage, sourcesStorage, sourceContentsStorage, sourceAliasesStorage, sourceLinksStorage, summarizerGate)
 
 	// Stories
 	produceAllActiveTasksToProcessStory := produce_tasks_to_process.NewStory(tasksStorage, producer)
-	processTaskStory := process_task.NewStory(log, tasksStorage, taskSourcesStorage, launcher, crawler)
+	processTaskStory := process_task.NewStory(log, tasksStorage, taskSourcesStorage, sourcesStorage, launcher, crawler)
+	purgeDeletedTasksStory := purge_deleted_tasks.NewStory(log, tasksStorage, sourcesStorage, retention)
+	reindexSourceContentsStory := reindex_source_contents.NewStory(log, sourceContentsStorage)
+	rekeySourcesStory := rekey_sources.NewStory(log, sourcesStorage)
 
 	// Actions
 	tasksToProcessProducer := produce_tasks_to_process_action.NewAction(log, produceAllActiveTasksToProcessStory)
 	tasksToProcessConsumer := consume_tasks_to_process.NewAction(log, consumer, processTaskStory, maxCountCrawlersInt)
+	deletedTasksPurger := purge_deleted_tasks_action.NewAction(log, purgeDeletedTasksStory)
+	sourceContentsReindexer := reindex_source_contents_action.NewAction(log, reindexSourceContentsStory)
+	sourcesRekeyer := rekey_sources_action.NewAction(log, rekeySourcesStory)
 
 	cmds := map[string]cmd{
 		"tasks-to-process-producer": worker.NewWithPeriod(tasksToProcessProducer.Run, tasksToProcessPeriod).Run,
 		"tasks-to-process-consumer": worker.New(tasksToProcessConsumer.Run).Run,
+		"purge-deleted-tasks":       worker.NewWithPeriod(deletedTasksPurger.Run, purgeDeletedTasksPeriod).Run,
+		// Однократная переиндексация текста, сохраненного до появления поиска
+		"reindex-source-contents": sourceContentsReindexer.Run,
+		// Однократное заполнение ключей адресов источников, сохраненных до нормализации URL
+		"rekey-sources": sourcesRekeyer.Run,
 	}
 
 	cmd, ok := cmds[cliSlug]
@@ -7,21 +7,33 @@ import (
 	"os"
 
 	api_activate_task "github.com/K1flar/crawlers/internal/handlers/activate_task"
+	api_compare_launches "github.com/K1flar/crawlers/internal/handlers/compare_launches"
 	api_create_task "github.com/K1flar/crawlers/internal/handlers/create_task"
+	api_delete_task "github.com/K1flar/crawlers/internal/handlers/delete_task"
+	api_export_task "github.com/K1flar/crawlers/internal/handlers/export_task"
 	api_get_protocol "github.com/K1flar/crawlers/internal/handlers/get_protocol"
+	api_get_source_content "github.com/K1flar/crawlers/internal/handlers/get_source_content"
+	api_get_source_history "github.com/K1flar/crawlers/internal/handlers/get_source_history"
 	api_get_sources "github.com/K1flar/crawlers/internal/handlers/get_sources"
 	api_get_task "github.com/K1flar/crawlers/internal/handlers/get_task"
 	api_get_task_status "github.com/K1flar/crawlers/internal/handlers/get_task_status"
 	api_get_tasks "github.com/K1flar/crawlers/internal/handlers/get_tasks"
+	api_search_sources "github.com/K1flar/crawlers/internal/handlers/search_sources"
 	api_stop_task "github.com/K1flar/crawlers/internal/handlers/stop_task"
 	api_update_task "github.com/K1flar/crawlers/internal/handlers/update_task"
 	"github.com/K1flar/crawlers/internal/message_broker/kafka"
 	"github.com/K1flar/crawlers/internal/message_broker/messages"
 	"github.com/K1flar/crawlers/internal/middlewares/cors"
 	"github.com/K1flar/crawlers/internal/storage/launches"
+	"github.com/K1flar/crawlers/internal/storage/source_aliases"
+	"github.com/K1flar/crawlers/internal/storage/source_contents"
+	"github.com/K1flar/crawlers/internal/storage/source_links"
 	"github.com/K1flar/crawlers/internal/storage/sources"
 	"github.com/K1flar/crawlers/internal/storage/tasks"
+	"github.com/K1flar/crawlers/internal/stories/compare_launches"
 	"github.com/K1flar/crawlers/internal/stories/create_task"
+	"github.com/K1flar/crawlers/internal/stories/delete_task"
+	"github.com/K1flar/crawlers/internal/stories/get_source_history"
 	"github.com/jmoiron/sqlx"
 	dotenv "github.com/joho/godotenv"
 	_ "github.com/lib/pq"
@@ -65,6 +77,9 @@ func main() {
 	tasksStorage := tasks.NewStorage(db)
 	sourcesStorage := sources.NewStorage(db)
 	launchesStorage := launches.NewStorage(db)
+	sourceContentsStorage := source_contents.NewStorage(db)
+	sourceAliasesStorage := source_aliases.NewStorage(db)
+	sourceLinksStorage := source_links.NewStorage(db)
 
 	kafkaBrokers := []string{
 		fmt.Sprintf("%s:%s", os.Getenv(kafkaHost), os.Getenv(kafkaPort)),
@@ -73,6 +88,9 @@ func main() {
 	producerTasksToProcess := kafka.NewProducer[messages.TaskToProcessMessage](kafkaBrokers, os.Getenv(tasksToProcessTopic))
 
 	createTaskStory := create_task.NewStory(log, tasksStorage, producerTasksToProcess)
+	deleteTaskStory := delete_task.NewStory(log, tasksStorage)
+	getSourceHistoryStory := get_source_history.NewStory(sourcesStorage, sourceContentsStorage)
+	compareLaunchesStory := compare_launches.NewStory(launchesStorage, sourcesStorage)
 
 	mux := http.NewServeMux()
 
@@ -87,12 +105,18 @@ func main() {
 	mux.Handle("POST /create-task", corsMW(http.HandlerFunc(api_create_task.New(log, createTaskStory).Handle)))
 	mux.Handle("POST /get-task", corsMW(http.HandlerFunc(api_get_task.New(log, tasksStorage, launchesStorage).Handle)))
 	mux.Handle("POST /get-task-status", corsMW(http.HandlerFunc(api_get_task_status.New(log, tasksStorage).Handle)))
-	mux.Handle("POST /get-sources", corsMW(http.HandlerFunc(api_get_sources.New(log, sourcesStorage).Handle)))
+	mux.Handle("POST /get-sources", corsMW(http.HandlerFunc(api_get_sources.New(log, sourcesStorage, sourceAliasesStorage, sourceLinksStorage).Handle)))
+	mux.Handle("POST /delete-task", corsMW(http.HandlerFunc(api_delete_task.New(log, deleteTaskStory).Handle)))
 	mux.Handle("POST /stop-task", corsMW(http.HandlerFunc(api_stop_task.New(log, tasksStorage).Handle)))
 	mux.Handle("POST /activate-task", corsMW(http.HandlerFunc(api_activate_task.New(log, tasksStorage, producerTasksToProcess).Handle)))
 	mux.Handle("POST /update-task", corsMW(http.HandlerFunc(api_update_task.New(log, tasksStorage).Handle)))
 	mux.Handle("POST /get-tasks", corsMW(http.HandlerFunc(api_get_tasks.New(log, tasksStorage).Handle)))
 	mux.Handle("POST /get-protocol", corsMW(http.HandlerFunc(api_get_protocol.New(log, sourcesStorage).Handle)))
+	mux.Handle("POST /get-source-content", corsMW(http.HandlerFunc(api_get_source_content.New(log, sourceContentsStorage).Handle)))
+	mux.Handle("POST /search-sources", corsMW(http.HandlerFunc(api_search_sources.New(log, sourceContentsStorage).Handle)))
+	mux.Handle("POST /get-source-history", corsMW(http.HandlerFunc(api_get_source_history.New(log, getSourceHistoryStory).Handle)))
+	mux.Handle("POST /compare-launches", corsMW(http.HandlerFunc(api_compare_launches.New(log, compareLaunchesStory).Handle)))
+	mux.Handle("GET /tasks/{id}/export", corsMW(http.HandlerFunc(api_export_task.New(log, tasksStorage, launchesStorage, sourcesStorage, sourceAliasesStorage, sourceLinksStorage, sourceContentsStorage).Handle)))
 
 	log.Info(fmt.Sprintf("Starting server on %s:%s", os.Getenv(serviceHost), os.Getenv(servicePort)))
 	if err := http.ListenAndServe(os.Getenv(serviceHost)+":"+os.Getenv(servicePort), mux); err != nil {
@@ -0,0 +1,4 @@
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS host_requests_per_second,
+    DROP COLUMN IF EXISTS host_burst,
+    DROP COLUMN IF EXISTS host_max_in_flight;
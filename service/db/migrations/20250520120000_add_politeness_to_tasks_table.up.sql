@@ -0,0 +1,4 @@
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS host_requests_per_second FLOAT NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS host_burst INT NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS host_max_in_flight INT NOT NULL DEFAULT 2;
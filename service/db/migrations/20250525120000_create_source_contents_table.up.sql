@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS source_contents (
+    source_id BIGINT NOT NULL REFERENCES sources(id) ON DELETE CASCADE,
+    launch_id BIGINT NOT NULL REFERENCES launches(id) ON DELETE CASCADE,
+    content BYTEA NOT NULL,
+    content_hash VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (source_id, launch_id)
+);
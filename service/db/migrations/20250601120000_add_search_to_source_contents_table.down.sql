@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_source_contents_tsv;
+
+ALTER TABLE source_contents DROP COLUMN IF EXISTS tsv;
@@ -0,0 +1,9 @@
+ALTER TABLE source_contents ADD COLUMN IF NOT EXISTS tsv TSVECTOR;
+
+-- Текст хранится сжатым, поэтому для уже сохраненных записей индексируем только заголовок
+UPDATE source_contents sc
+SET tsv = setweight(to_tsvector('russian', s.title), 'A')
+FROM sources s
+WHERE s.id = sc.source_id AND sc.tsv IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_source_contents_tsv ON source_contents USING GIN (tsv);
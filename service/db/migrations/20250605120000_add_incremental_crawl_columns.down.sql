@@ -0,0 +1,10 @@
+ALTER TABLE launches
+    DROP COLUMN IF EXISTS sources_new,
+    DROP COLUMN IF EXISTS sources_changed,
+    DROP COLUMN IF EXISTS sources_unchanged,
+    DROP COLUMN IF EXISTS sources_gone;
+
+ALTER TABLE sources
+    DROP COLUMN IF EXISTS etag,
+    DROP COLUMN IF EXISTS last_modified,
+    DROP COLUMN IF EXISTS content_hash;
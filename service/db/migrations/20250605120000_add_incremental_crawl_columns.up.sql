@@ -0,0 +1,10 @@
+ALTER TABLE sources
+    ADD COLUMN IF NOT EXISTS etag TEXT,
+    ADD COLUMN IF NOT EXISTS last_modified TEXT,
+    ADD COLUMN IF NOT EXISTS content_hash VARCHAR(64);
+
+ALTER TABLE launches
+    ADD COLUMN IF NOT EXISTS sources_new INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS sources_changed INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS sources_unchanged INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS sources_gone INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS source_versions (
+    id BIGSERIAL PRIMARY KEY,
+    source_id BIGINT NOT NULL REFERENCES sources(id) ON DELETE CASCADE,
+    version INT NOT NULL,
+    launch_id BIGINT REFERENCES launches(id) ON DELETE SET NULL,
+    title TEXT NOT NULL,
+    status TEXT NOT NULL,
+    content_hash VARCHAR(64),
+    created_at TIMESTAMP NOT NULL,
+
+    UNIQUE (source_id, version)
+);
+
+-- Текущее состояние уже собранных источников становится первой версией
+INSERT INTO source_versions (source_id, version, launch_id, title, status, content_hash, created_at)
+SELECT id, 1, (SELECT MAX(launch_id) FROM source_contents WHERE source_id = sources.id), title, status, content_hash, updated_at
+FROM sources
+ON CONFLICT (source_id, version) DO NOTHING;
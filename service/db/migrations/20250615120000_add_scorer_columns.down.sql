@@ -0,0 +1,4 @@
+ALTER TABLE tasks DROP COLUMN IF EXISTS scorer;
+ALTER TABLE tasks DROP COLUMN IF EXISTS scorer_params;
+
+ALTER TABLE tasks_x_sources DROP COLUMN IF EXISTS scorer;
@@ -0,0 +1,4 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS scorer TEXT NOT NULL DEFAULT 'bm25';
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS scorer_params JSONB NOT NULL DEFAULT '{}';
+
+ALTER TABLE tasks_x_sources ADD COLUMN IF NOT EXISTS scorer TEXT NOT NULL DEFAULT 'bm25';
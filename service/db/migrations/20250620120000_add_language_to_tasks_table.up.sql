@@ -0,0 +1 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS language TEXT NOT NULL DEFAULT 'auto';
@@ -0,0 +1,8 @@
+ALTER TABLE sources DROP COLUMN IF EXISTS description;
+ALTER TABLE sources DROP COLUMN IF EXISTS language;
+ALTER TABLE sources DROP COLUMN IF EXISTS canonical_url;
+ALTER TABLE sources DROP COLUMN IF EXISTS author;
+ALTER TABLE sources DROP COLUMN IF EXISTS site_name;
+ALTER TABLE sources DROP COLUMN IF EXISTS image_url;
+ALTER TABLE sources DROP COLUMN IF EXISTS published_at;
+ALTER TABLE sources DROP COLUMN IF EXISTS modified_at;
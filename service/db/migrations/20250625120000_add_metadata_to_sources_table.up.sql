@@ -0,0 +1,8 @@
+ALTER TABLE sources ADD COLUMN IF NOT EXISTS description TEXT;
+ALTER TABLE sources ADD COLUMN IF NOT EXISTS language VARCHAR(16);
+ALTER TABLE sources ADD COLUMN IF NOT EXISTS canonical_url TEXT;
+ALTER TABLE sources ADD COLUMN IF NOT EXISTS author TEXT;
+ALTER TABLE sources ADD COLUMN IF NOT EXISTS site_name TEXT;
+ALTER TABLE sources ADD COLUMN IF NOT EXISTS image_url TEXT;
+ALTER TABLE sources ADD COLUMN IF NOT EXISTS published_at TIMESTAMP;
+ALTER TABLE sources ADD COLUMN IF NOT EXISTS modified_at TIMESTAMP;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS source_aliases;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS duplicate_distance;
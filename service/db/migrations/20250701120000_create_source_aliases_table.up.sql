@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS source_aliases (
+    launch_id BIGINT NOT NULL REFERENCES launches(id) ON DELETE CASCADE,
+    source_id BIGINT NOT NULL REFERENCES sources(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    distance INT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (launch_id, source_id, url)
+);
+
+-- Допустимое расстояние Хэмминга между отпечатками SimHash, отрицательное значение отключает поиск дублей
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS duplicate_distance INT NOT NULL DEFAULT 3;
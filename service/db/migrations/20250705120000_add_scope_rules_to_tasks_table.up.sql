@@ -0,0 +1,2 @@
+-- Правила обхода ссылок, по умолчанию не загружаются медиафайлы, архивы и исполняемые файлы
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS scope_rules JSONB NOT NULL DEFAULT '{"blockedExtensions": ["jpg", "jpeg", "png", "gif", "webp", "svg", "ico", "bmp", "mp3", "mp4", "avi", "mov", "webm", "zip", "rar", "7z", "gz", "tar", "exe", "dmg", "iso", "apk", "css", "js", "woff", "woff2", "ttf"]}';
@@ -0,0 +1,14 @@
+-- Ссылки между источниками запуска: текст ссылки, атрибут rel и текст вокруг нее
+CREATE TABLE IF NOT EXISTS source_links (
+    launch_id BIGINT NOT NULL REFERENCES launches(id) ON DELETE CASCADE,
+    from_source_id BIGINT NOT NULL REFERENCES sources(id) ON DELETE CASCADE,
+    to_source_id BIGINT NOT NULL REFERENCES sources(id) ON DELETE CASCADE,
+    anchor TEXT NOT NULL DEFAULT '',
+    rel TEXT[] NOT NULL DEFAULT '{}',
+    snippet TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (launch_id, from_source_id, to_source_id)
+);
+
+CREATE INDEX IF NOT EXISTS source_links_to_source_id_idx ON source_links (launch_id, to_source_id);
@@ -0,0 +1,3 @@
+ALTER TABLE tasks_x_sources DROP COLUMN IF EXISTS authority_score;
+ALTER TABLE tasks_x_sources DROP COLUMN IF EXISTS hub_score;
+ALTER TABLE tasks_x_sources DROP COLUMN IF EXISTS pagerank;
@@ -0,0 +1,4 @@
+-- Оценки источника по графу ссылок запуска: PageRank и HITS
+ALTER TABLE tasks_x_sources ADD COLUMN IF NOT EXISTS pagerank DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE tasks_x_sources ADD COLUMN IF NOT EXISTS hub_score DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE tasks_x_sources ADD COLUMN IF NOT EXISTS authority_score DOUBLE PRECISION NOT NULL DEFAULT 0;
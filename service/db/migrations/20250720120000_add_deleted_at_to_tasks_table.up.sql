@@ -0,0 +1,4 @@
+-- Удаленные задачи скрываются сразу, а окончательно удаляются после срока хранения
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS tasks_deleted_at_idx ON tasks (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE launches DROP COLUMN IF EXISTS summary_source_id;
+ALTER TABLE launches DROP COLUMN IF EXISTS summary;
@@ -0,0 +1,3 @@
+-- Краткий пересказ самого релевантного источника запуска
+ALTER TABLE launches ADD COLUMN IF NOT EXISTS summary TEXT;
+ALTER TABLE launches ADD COLUMN IF NOT EXISTS summary_source_id BIGINT REFERENCES sources(id) ON DELETE SET NULL;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS task_configs;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS config_version;
+ALTER TABLE tasks DROP COLUMN IF EXISTS schedule;
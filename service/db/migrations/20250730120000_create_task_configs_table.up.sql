@@ -0,0 +1,34 @@
+-- Версии параметров обхода задачи, версия 1 - параметры при создании
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS schedule TEXT NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS config_version INT NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS task_configs (
+    task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    version INT NOT NULL,
+    config JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (task_id, version)
+);
+
+INSERT INTO task_configs (task_id, version, config, created_at)
+SELECT
+    id,
+    config_version,
+    jsonb_build_object(
+        'depthLevel', depth_level,
+        'minWeight', min_weight,
+        'maxSources', max_sources,
+        'maxNeighboursForSource', max_neighbours_for_source,
+        'hostRequestsPerSecond', host_requests_per_second,
+        'hostBurst', host_burst,
+        'hostMaxInFlight', host_max_in_flight,
+        'scorer', scorer,
+        'scorerParams', scorer_params,
+        'language', language,
+        'duplicateDistance', duplicate_distance,
+        'scopeRules', scope_rules,
+        'schedule', schedule
+    ),
+    updated_at
+FROM tasks
+ON CONFLICT (task_id, version) DO NOTHING;
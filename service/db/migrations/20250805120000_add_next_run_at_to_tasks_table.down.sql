@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS tasks_next_run_at_idx;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS next_run_at;
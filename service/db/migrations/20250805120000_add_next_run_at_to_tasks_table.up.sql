@@ -0,0 +1,6 @@
+-- Время следующего запуска задачи по ее расписанию
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS next_run_at TIMESTAMP;
+
+UPDATE tasks SET next_run_at = COALESCE(processed_at, created_at) + INTERVAL '1 day' WHERE next_run_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS tasks_next_run_at_idx ON tasks (next_run_at) WHERE status = 'active' AND deleted_at IS NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS sources_url_key_idx;
+
+ALTER TABLE sources DROP COLUMN IF EXISTS url_key;
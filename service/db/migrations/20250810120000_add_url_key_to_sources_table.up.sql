@@ -0,0 +1,5 @@
+-- Ключ нормализованного адреса без схемы: http и https версии страницы хранятся одним источником.
+-- Ключи источников, сохраненных до нормализации, заполняет команда rekey-sources, она же объединяет их дубли
+ALTER TABLE sources ADD COLUMN IF NOT EXISTS url_key TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS sources_url_key_idx ON sources (url_key);
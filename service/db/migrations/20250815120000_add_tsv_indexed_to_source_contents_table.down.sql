@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS source_contents_not_tsv_indexed_idx;
+
+ALTER TABLE source_contents DROP COLUMN IF EXISTS tsv_indexed;
@@ -0,0 +1,5 @@
+-- Текст страницы хранится сжатым, и при добавлении поиска по уже сохраненным записям был проиндексирован только заголовок.
+-- Такие записи переиндексирует команда reindex-source-contents
+ALTER TABLE source_contents ADD COLUMN IF NOT EXISTS tsv_indexed BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS source_contents_not_tsv_indexed_idx ON source_contents (source_id, launch_id) WHERE NOT tsv_indexed;
@@ -0,0 +1 @@
+ALTER TABLE launches DROP COLUMN IF EXISTS skipped;
@@ -0,0 +1,2 @@
+-- Количество URL, которые краулер не стал загружать, по причинам: robots.txt, правила обхода, ошибка загрузки
+ALTER TABLE launches ADD COLUMN IF NOT EXISTS skipped JSONB NOT NULL DEFAULT '{}';
@@ -0,0 +1,9 @@
+DELETE FROM source_links WHERE to_source_id IS NULL;
+
+ALTER TABLE source_links DROP CONSTRAINT IF EXISTS source_links_pkey;
+DELETE FROM source_links a USING source_links b
+WHERE a.launch_id = b.launch_id AND a.from_source_id = b.from_source_id AND a.to_source_id = b.to_source_id AND a.to_url > b.to_url;
+ALTER TABLE source_links ALTER COLUMN to_source_id SET NOT NULL;
+ALTER TABLE source_links ADD PRIMARY KEY (launch_id, from_source_id, to_source_id);
+
+ALTER TABLE source_links DROP COLUMN IF EXISTS to_url;
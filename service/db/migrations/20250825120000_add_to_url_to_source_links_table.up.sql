@@ -0,0 +1,10 @@
+-- Сохраняются все найденные ссылки источника: адрес цели хранится всегда, источник - если цель сохранена в базе знаний
+ALTER TABLE source_links ADD COLUMN IF NOT EXISTS to_url TEXT;
+
+UPDATE source_links sl SET to_url = s.url FROM sources s WHERE s.id = sl.to_source_id AND sl.to_url IS NULL;
+
+ALTER TABLE source_links ALTER COLUMN to_url SET NOT NULL;
+
+ALTER TABLE source_links DROP CONSTRAINT IF EXISTS source_links_pkey;
+ALTER TABLE source_links ALTER COLUMN to_source_id DROP NOT NULL;
+ALTER TABLE source_links ADD PRIMARY KEY (launch_id, from_source_id, to_url);
@@ -24,8 +24,8 @@ func NewAction(
 }
 
 func (a *Action) Run(ctx context.Context) {
-	err := a.story.ProduceAll(ctx)
+	err := a.story.ProduceDue(ctx)
 	if err != nil {
-		a.log.Error(fmt.Sprintf("failed to produce active tasks: %s", err.Error()))
+		a.log.Error(fmt.Sprintf("failed to produce due tasks: %s", err.Error()))
 	}
 }
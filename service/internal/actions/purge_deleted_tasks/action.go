@@ -0,0 +1,31 @@
+package purge_deleted_tasks
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/K1flar/crawlers/internal/stories"
+)
+
+type Action struct {
+	log   *slog.Logger
+	story stories.PurgeDeletedTasks
+}
+
+func NewAction(
+	log *slog.Logger,
+	story stories.PurgeDeletedTasks,
+) *Action {
+	return &Action{
+		log:   log,
+		story: story,
+	}
+}
+
+func (a *Action) Run(ctx context.Context) {
+	err := a.story.Purge(ctx)
+	if err != nil {
+		a.log.Error(fmt.Sprintf("failed to purge deleted tasks: %s", err.Error()))
+	}
+}
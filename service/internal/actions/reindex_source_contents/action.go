@@ -0,0 +1,31 @@
+package reindex_source_contents
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/K1flar/crawlers/internal/stories"
+)
+
+type Action struct {
+	log   *slog.Logger
+	story stories.ReindexSourceContents
+}
+
+func NewAction(
+	log *slog.Logger,
+	story stories.ReindexSourceContents,
+) *Action {
+	return &Action{
+		log:   log,
+		story: story,
+	}
+}
+
+func (a *Action) Run(ctx context.Context) {
+	err := a.story.Reindex(ctx)
+	if err != nil {
+		a.log.Error(fmt.Sprintf("failed to reindex source contents: %s", err.Error()))
+	}
+}
@@ -0,0 +1,31 @@
+package rekey_sources
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/K1flar/crawlers/internal/stories"
+)
+
+type Action struct {
+	log   *slog.Logger
+	story stories.RekeySources
+}
+
+func NewAction(
+	log *slog.Logger,
+	story stories.RekeySources,
+) *Action {
+	return &Action{
+		log:   log,
+		story: story,
+	}
+}
+
+func (a *Action) Run(ctx context.Context) {
+	err := a.story.Rekey(ctx)
+	if err != nil {
+		a.log.Error(fmt.Sprintf("failed to rekey sources: %s", err.Error()))
+	}
+}
@@ -4,6 +4,8 @@ var (
 	InvalidQuery      = New("invalid_query")
 	UnavailableSource = New("unavailable_source")
 	EntityNotFound    = New("entity_not_found")
+	UnknownScorer     = New("unknown_scorer")
+	TaskInProcessing  = New("task_in_processing")
 
 	SearxError       = New("searx_error")
 	ZeroStartSources = New("zero_start_sources")
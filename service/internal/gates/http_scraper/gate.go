@@ -0,0 +1,120 @@
+package http_scraper
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	page_models "github.com/K1flar/crawlers/internal/models/page"
+	"github.com/K1flar/crawlers/internal/services/content_extractor"
+	"github.com/K1flar/crawlers/internal/services/link_extractor"
+	"github.com/PuerkitoBio/goquery"
+)
+
+const (
+	defaultTimeout = 10 * time.Second
+
+	// Ограничение на размер тела ответа, чтобы не читать в память огромные файлы
+	maxBodySize = 10 << 20
+)
+
+// Gate загружает страницу обычным HTTP-запросом без выполнения JS
+type Gate struct {
+	client    *http.Client
+	userAgent string
+}
+
+func NewGate(userAgent string) *Gate {
+	return &Gate{
+		client:    &http.Client{},
+		userAgent: userAgent,
+	}
+}
+
+func (g *Gate) GetPage(ctx context.Context, url string, known *page_models.Known) (*page_models.Page, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", g.userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	if known != nil {
+		if known.ETag != "" {
+			req.Header.Set("If-None-Match", known.ETag)
+		}
+
+		if known.LastModified != "" {
+			req.Header.Set("If-Modified-Since", known.LastModified)
+		}
+	}
+
+	res, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	page := &page_models.Page{
+		// URL после редиректов
+		URL:          res.Request.URL.String(),
+		Status:       page_models.StatusUnavailable,
+		ETag:         res.Header.Get("ETag"),
+		LastModified: res.Header.Get("Last-Modified"),
+	}
+
+	if res.StatusCode == http.StatusNotModified && known != nil {
+		page.Status = page_models.StatusNotModified
+
+		// Сервер не обязан повторять валидаторы в ответе 304
+		if page.ETag == "" {
+			page.ETag = known.ETag
+		}
+
+		if page.LastModified == "" {
+			page.LastModified = known.LastModified
+		}
+
+		return page, nil
+	}
+
+	if res.StatusCode != http.StatusOK || !isHTML(res.Header.Get("Content-Type")) {
+		return page, nil
+	}
+
+	doc, err := goquery.NewDocumentFromReader(io.LimitReader(res.Body, maxBodySize))
+	if err != nil {
+		return nil, err
+	}
+
+	page.Status = page_models.StatusAvailable
+	page.Title = strings.TrimSpace(doc.Find("title").First().Text())
+	page.Links = link_extractor.Extract(doc, res.Request.URL)
+	page.Metadata = content_extractor.ExtractMetadata(doc, res.Request.URL)
+
+	content := content_extractor.Extract(doc)
+	page.Content = content.Text
+	page.Headings = content.Headings
+
+	return page, nil
+}
+
+func isHTML(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
@@ -0,0 +1,84 @@
+package http_summarizer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+)
+
+type Client interface {
+	Do(ctx context.Context, req *http.Request) (*http.Response, error)
+}
+
+// Gate - адаптер внешнего сервиса пересказа: POST /summarize {title, text} -> {summary}
+type Gate struct {
+	log    *slog.Logger
+	client Client
+}
+
+func NewGate(
+	log *slog.Logger,
+	client Client,
+) *Gate {
+	return &Gate{log, client}
+}
+
+type dtoRequest struct {
+	Title string `json:"title"`
+	Text  string `json:"text"`
+}
+
+type dtoResponse struct {
+	Summary string `json:"summary"`
+}
+
+func (g *Gate) Summarize(ctx context.Context, title string, text string) (string, error) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			g.log.Error(fmt.Sprintf(`error to summarize "%s": %s`, title, err))
+		}
+	}()
+
+	body, err := json.Marshal(dtoRequest{Title: title, Text: text})
+	if err != nil {
+		return "", err
+	}
+
+	req := &http.Request{
+		Method: http.MethodPost,
+		URL:    &url.URL{Path: "/summarize"},
+		Header: http.Header{"Content-Type": []string{"application/json"}},
+		Body:   io.NopCloser(bytes.NewReader(body)),
+	}
+
+	res, err := g.client.Do(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		err = fmt.Errorf("summarizer responded with status %d: %s", res.StatusCode, b)
+		return "", err
+	}
+
+	var dto dtoResponse
+	err = json.Unmarshal(b, &dto)
+	if err != nil {
+		return "", err
+	}
+
+	return dto.Summary, nil
+}
@@ -2,6 +2,7 @@ package gates
 
 import (
 	"context"
+	"time"
 
 	"github.com/K1flar/crawlers/internal/models/page"
 )
@@ -11,5 +12,16 @@ type SearchSystem interface {
 }
 
 type WebScraper interface {
-	GetPage(ctx context.Context, url string) (*page.Page, error)
+	// GetPage загружает страницу; known - валидаторы прошлого запуска для условного запроса, может быть nil
+	GetPage(ctx context.Context, url string, known *page.Known) (*page.Page, error)
+}
+
+type Summarizer interface {
+	// Summarize возвращает краткий пересказ текста страницы
+	Summarize(ctx context.Context, title string, text string) (string, error)
+}
+
+type RobotsTxt interface {
+	Allowed(ctx context.Context, url string) (bool, error)
+	CrawlDelay(ctx context.Context, url string) (time.Duration, error)
 }
@@ -0,0 +1,94 @@
+package page_fetcher
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/K1flar/crawlers/internal/gates"
+	page_models "github.com/K1flar/crawlers/internal/models/page"
+)
+
+type Mode string
+
+const (
+	// ModeStatic - только HTTP-запрос без браузера
+	ModeStatic Mode = "static"
+	// ModeBrowser - только headless Chrome
+	ModeBrowser Mode = "browser"
+	// ModeAuto - сначала HTTP-запрос, браузер если страница похожа на отрисованную через JS
+	ModeAuto Mode = "auto"
+)
+
+const (
+	// Минимальное количество слов, при котором страница считается отрисованной на сервере
+	minStaticWords = 50
+)
+
+func ParseMode(s string) (Mode, error) {
+	switch mode := Mode(strings.ToLower(strings.TrimSpace(s))); mode {
+	case ModeStatic, ModeBrowser, ModeAuto:
+		return mode, nil
+	}
+
+	return "", fmt.Errorf("unknown web scraper mode [%s]", s)
+}
+
+// Gate выбирает способ загрузки страницы в зависимости от режима
+type Gate struct {
+	log     *slog.Logger
+	static  gates.WebScraper
+	browser gates.WebScraper
+	mode    Mode
+}
+
+func NewGate(
+	log *slog.Logger,
+	static gates.WebScraper,
+	browser gates.WebScraper,
+	mode Mode,
+) *Gate {
+	return &Gate{
+		log:     log,
+		static:  static,
+		browser: browser,
+		mode:    mode,
+	}
+}
+
+func (g *Gate) GetPage(ctx context.Context, url string, known *page_models.Known) (*page_models.Page, error) {
+	switch g.mode {
+	case ModeStatic:
+		return g.static.GetPage(ctx, url, known)
+	case ModeBrowser:
+		return g.browser.GetPage(ctx, url, known)
+	}
+
+	page, err := g.static.GetPage(ctx, url, known)
+	if err == nil && !looksJSRendered(page) {
+		return page, nil
+	}
+
+	if err != nil {
+		g.log.Debug(fmt.Sprintf("failed to get page [%s] without browser: %s", url, err))
+	} else {
+		g.log.Debug(fmt.Sprintf("page [%s] looks js-rendered, fallback to browser", url))
+	}
+
+	return g.browser.GetPage(ctx, url, known)
+}
+
+// looksJSRendered считает страницу отрисованной через JS, если сервер отдал
+// успешный ответ почти без текста (пустой контейнер для SPA)
+func looksJSRendered(page *page_models.Page) bool {
+	if page == nil {
+		return true
+	}
+
+	if page.Status != page_models.StatusAvailable {
+		return false
+	}
+
+	return len(strings.Fields(page.Content)) < minStaticWords
+}
@@ -0,0 +1,184 @@
+package robots_txt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	defaultTimeout = 10 * time.Second
+	// RFC 9309 рекомендует не кэшировать robots.txt дольше суток
+	defaultCacheTTL = 24 * time.Hour
+	// Ошибки сервера кэшируются ненадолго, чтобы не запрещать хост надолго из-за временного сбоя
+	serverErrorCacheTTL = time.Minute
+	// Сетевая ошибка кэшируется совсем ненадолго: ссылки на недоступный хост не ждут таймаута каждая
+	networkErrorCacheTTL = 15 * time.Second
+
+	maxBodySize = 500 << 10
+)
+
+type cacheEntry struct {
+	robots    robots
+	err       error // сетевая ошибка загрузки
+	expiresAt time.Time
+}
+
+// Gate загружает и кэширует robots.txt по хостам
+type Gate struct {
+	client    *http.Client
+	userAgent string
+	ttl       time.Duration
+	now       func() time.Time
+
+	mu    sync.RWMutex
+	cache map[string]cacheEntry
+	group singleflight.Group
+}
+
+func NewGate(userAgent string) *Gate {
+	return &Gate{
+		client:    &http.Client{},
+		userAgent: userAgent,
+		ttl:       defaultCacheTTL,
+		now:       time.Now,
+		cache:     make(map[string]cacheEntry),
+	}
+}
+
+func (g *Gate) Allowed(ctx context.Context, rawURL string) (bool, error) {
+	u, err := parseURL(rawURL)
+	if err != nil {
+		return false, err
+	}
+
+	robots, err := g.get(ctx, u.Scheme, u.Host)
+	if err != nil {
+		return false, err
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+
+	return robots.allowed(g.userAgent, path), nil
+}
+
+func (g *Gate) CrawlDelay(ctx context.Context, rawURL string) (time.Duration, error) {
+	u, err := parseURL(rawURL)
+	if err != nil {
+		return 0, err
+	}
+
+	robots, err := g.get(ctx, u.Scheme, u.Host)
+	if err != nil {
+		return 0, err
+	}
+
+	return robots.crawlDelay(g.userAgent), nil
+}
+
+func parseURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse url [%s]: %w", rawURL, err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("unsupported url [%s]", rawURL)
+	}
+
+	return u, nil
+}
+
+func (g *Gate) get(ctx context.Context, scheme, host string) (robots, error) {
+	key := scheme + "://" + host
+
+	g.mu.RLock()
+	entry, ok := g.cache[key]
+	g.mu.RUnlock()
+
+	if ok && g.now().Before(entry.expiresAt) {
+		return entry.robots, entry.err
+	}
+
+	// Параллельные запросы к одному хосту ждут одной загрузки. Загрузка не зависит от контекста
+	// вызвавшего ее запроса, чтобы его отмена не обрывала ее для остальных
+	ch := g.group.DoChan(key, func() (any, error) {
+		robots, ttl, err := g.fetch(key)
+
+		g.mu.Lock()
+		g.cache[key] = cacheEntry{
+			robots:    robots,
+			err:       err,
+			expiresAt: g.now().Add(ttl),
+		}
+		g.mu.Unlock()
+
+		return robots, err
+	})
+
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return robots{}, res.Err
+		}
+
+		return res.Val.(robots), nil
+	case <-ctx.Done():
+		return robots{}, ctx.Err()
+	}
+}
+
+func (g *Gate) fetch(origin string) (robots, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, origin+"/robots.txt", nil)
+	if err != nil {
+		return robots{}, 0, err
+	}
+
+	req.Header.Set("User-Agent", g.userAgent)
+
+	res, err := g.client.Do(req)
+	if err != nil {
+		return robots{}, networkErrorCacheTTL, fmt.Errorf("failed to fetch robots.txt from [%s]: %w", origin, err)
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode >= 200 && res.StatusCode < 300:
+		return parse(io.LimitReader(res.Body, maxBodySize)), g.ttl, nil
+	case res.StatusCode == http.StatusTooManyRequests:
+		// 429 по RFC 9309 означает недоступность robots.txt, как и ошибка сервера
+		return disallowAll(), serverErrorCacheTTL, nil
+	case res.StatusCode >= 400 && res.StatusCode < 500:
+		// robots.txt отсутствует - ограничений нет
+		return robots{}, g.ttl, nil
+	default:
+		return disallowAll(), serverErrorCacheTTL, nil
+	}
+}
+
+func disallowAll() robots {
+	return robots{
+		groups: []group{
+			{
+				userAgents: []string{"*"},
+				rules:      []rule{{allow: false, path: "/"}},
+			},
+		},
+	}
+}
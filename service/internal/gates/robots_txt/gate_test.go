@@ -0,0 +1,263 @@
+package robots_txt
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func newTestGate(c *clock) *Gate {
+	g := NewGate("crawlers-bot/1.0")
+	g.now = c.Now
+
+	return g
+}
+
+// flakyTransport отвечает сетевой ошибкой на первые fail запросов
+type flakyTransport struct {
+	fail  atomic.Int32
+	calls atomic.Int32
+}
+
+func (t *flakyTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.calls.Add(1)
+
+	if t.fail.Add(-1) >= 0 {
+		return nil, errors.New("connection reset")
+	}
+
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestNetworkErrorIsCachedBriefly(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+	}))
+	defer srv.Close()
+
+	transport := &flakyTransport{}
+	transport.fail.Store(1)
+
+	c := &clock{now: time.Now()}
+	g := newTestGate(c)
+	g.client = &http.Client{Transport: transport}
+
+	if _, err := g.Allowed(context.Background(), srv.URL+"/a"); err == nil {
+		t.Fatal("expected error for unreachable host")
+	}
+
+	c.now = c.now.Add(networkErrorCacheTTL / 2)
+	if _, err := g.Allowed(context.Background(), srv.URL+"/b"); err == nil || transport.calls.Load() != 1 {
+		t.Fatalf("got %d fetches, want cached network error without refetch", transport.calls.Load())
+	}
+
+	c.now = c.now.Add(networkErrorCacheTTL)
+
+	allowed, err := g.Allowed(context.Background(), srv.URL+"/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !allowed || transport.calls.Load() != 2 {
+		t.Fatalf("allowed %v after %d fetches, want refetch after network error expired", allowed, transport.calls.Load())
+	}
+}
+
+func TestServerErrorIsCachedBriefly(t *testing.T) {
+	var (
+		calls  atomic.Int32
+		status atomic.Int32
+	)
+	status.Store(http.StatusServiceUnavailable)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer srv.Close()
+
+	c := &clock{now: time.Now()}
+	g := newTestGate(c)
+
+	if allowed, _ := g.Allowed(context.Background(), srv.URL+"/a"); allowed {
+		t.Fatal("5xx must disallow crawling")
+	}
+
+	status.Store(http.StatusNotFound)
+
+	c.now = c.now.Add(serverErrorCacheTTL / 2)
+	if allowed, _ := g.Allowed(context.Background(), srv.URL+"/a"); allowed || calls.Load() != 1 {
+		t.Fatal("5xx must be cached for serverErrorCacheTTL")
+	}
+
+	c.now = c.now.Add(serverErrorCacheTTL)
+	if allowed, _ := g.Allowed(context.Background(), srv.URL+"/a"); !allowed || calls.Load() != 2 {
+		t.Fatal("5xx must expire after serverErrorCacheTTL")
+	}
+}
+
+func TestCanceledCallerDoesNotBreakSharedFetch(t *testing.T) {
+	release := make(chan struct{})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+	}))
+	defer srv.Close()
+
+	g := newTestGate(&clock{now: time.Now()})
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	canceled := make(chan error, 1)
+	go func() {
+		_, err := g.Allowed(ctx, srv.URL+"/a")
+		canceled <- err
+	}()
+
+	waiting := make(chan bool, 1)
+	go func() {
+		allowed, _ := g.Allowed(context.Background(), srv.URL+"/private")
+		waiting <- allowed
+	}()
+
+	cancel()
+	if err := <-canceled; err == nil {
+		t.Fatal("canceled caller must get context error")
+	}
+
+	close(release)
+	if allowed := <-waiting; allowed {
+		t.Fatal("other caller must get robots.txt fetched after cancellation")
+	}
+
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	if len(g.cache) != 1 {
+		t.Fatal("robots.txt must be cached after cancellation of one caller")
+	}
+}
+
+func TestGateHandlesResponses(t *testing.T) {
+	const rules = "User-agent: *\nDisallow: /private\nCrawl-delay: 2\n\nUser-agent: crawlers-bot\nDisallow: /bot\n"
+
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		path        string
+		wantAllowed bool
+		wantDelay   time.Duration
+		ttl         time.Duration
+	}{
+		{"rules for any agent are overridden", http.StatusOK, rules, "/private", true, 0, defaultCacheTTL},
+		{"rules for own agent", http.StatusOK, rules, "/bot/a", false, 0, defaultCacheTTL},
+		{"crawl delay", http.StatusOK, "User-agent: *\nCrawl-delay: 1.5\n", "/a", true, 1500 * time.Millisecond, defaultCacheTTL},
+		{"not found allows everything", http.StatusNotFound, "", "/private", true, 0, defaultCacheTTL},
+		{"forbidden allows everything", http.StatusForbidden, "", "/private", true, 0, defaultCacheTTL},
+		{"server error disallows everything", http.StatusInternalServerError, "", "/a", false, 0, serverErrorCacheTTL},
+		{"unavailable disallows everything", http.StatusServiceUnavailable, "", "/a", false, 0, serverErrorCacheTTL},
+		{"too many requests disallows everything", http.StatusTooManyRequests, "", "/a", false, 0, serverErrorCacheTTL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+
+				if r.URL.Path != "/robots.txt" || r.Header.Get("User-Agent") != "crawlers-bot/1.0" {
+					t.Errorf("unexpected request %s with agent %s", r.URL.Path, r.Header.Get("User-Agent"))
+				}
+
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			c := &clock{now: time.Now()}
+			g := newTestGate(c)
+
+			allowed, err := g.Allowed(context.Background(), srv.URL+tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if allowed != tt.wantAllowed {
+				t.Fatalf("allowed: got %v, want %v", allowed, tt.wantAllowed)
+			}
+
+			delay, err := g.CrawlDelay(context.Background(), srv.URL+tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if delay != tt.wantDelay {
+				t.Fatalf("crawl delay: got %s, want %s", delay, tt.wantDelay)
+			}
+
+			c.now = c.now.Add(tt.ttl - time.Second)
+			g.Allowed(context.Background(), srv.URL+tt.path)
+
+			if calls.Load() != 1 {
+				t.Fatalf("got %d fetches before ttl expired, want 1", calls.Load())
+			}
+
+			c.now = c.now.Add(2 * time.Second)
+			g.Allowed(context.Background(), srv.URL+tt.path)
+
+			if calls.Load() != 2 {
+				t.Fatalf("got %d fetches after ttl expired, want 2", calls.Load())
+			}
+		})
+	}
+}
+
+func TestGateCachesPerOrigin(t *testing.T) {
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+	}))
+	defer srv.Close()
+
+	g := newTestGate(&clock{now: time.Now()})
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if _, err := g.Allowed(context.Background(), srv.URL+"/a"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Fatalf("got %d fetches for concurrent checks of one host, want 1", calls.Load())
+	}
+
+	if _, err := g.Allowed(context.Background(), "ftp://"+srv.Listener.Addr().String()+"/a"); err == nil {
+		t.Fatal("expected error for unsupported scheme")
+	}
+}
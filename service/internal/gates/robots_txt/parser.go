@@ -0,0 +1,205 @@
+package robots_txt
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Правила robots.txt для хоста (RFC 9309)
+type robots struct {
+	groups []group
+}
+
+type group struct {
+	userAgents []string
+	rules      []rule
+	crawlDelay time.Duration
+}
+
+type rule struct {
+	allow bool
+	path  string
+}
+
+func parse(r io.Reader) robots {
+	var (
+		res robots
+		cur *group
+		// Подряд идущие user-agent относятся к одной группе
+		lastWasAgent bool
+	)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if !lastWasAgent {
+				res.groups = append(res.groups, group{})
+				cur = &res.groups[len(res.groups)-1]
+			}
+
+			cur.userAgents = append(cur.userAgents, strings.ToLower(value))
+			lastWasAgent = true
+		case "allow", "disallow":
+			lastWasAgent = false
+
+			// Правила до первого user-agent игнорируются, пустой disallow ничего не запрещает
+			if cur == nil || value == "" {
+				continue
+			}
+
+			cur.rules = append(cur.rules, rule{
+				allow: key == "allow",
+				path:  value,
+			})
+		case "crawl-delay":
+			lastWasAgent = false
+
+			if cur == nil {
+				continue
+			}
+
+			seconds, err := strconv.ParseFloat(value, 64)
+			if err != nil || seconds < 0 {
+				continue
+			}
+
+			cur.crawlDelay = time.Duration(seconds * float64(time.Second))
+		default:
+			lastWasAgent = false
+		}
+	}
+
+	return res
+}
+
+// groupFor возвращает группу для user-agent: наиболее специфичную по длине
+// совпавшего токена, иначе группу "*"
+func (r robots) groupFor(userAgent string) *group {
+	userAgent = strings.ToLower(userAgent)
+
+	var (
+		best     *group
+		bestLen  int
+		wildcard *group
+	)
+
+	for i := range r.groups {
+		g := &r.groups[i]
+
+		for _, agent := range g.userAgents {
+			if agent == "*" {
+				if wildcard == nil {
+					wildcard = g
+				}
+				continue
+			}
+
+			if strings.Contains(userAgent, agent) && len(agent) > bestLen {
+				best = g
+				bestLen = len(agent)
+			}
+		}
+	}
+
+	if best != nil {
+		return best
+	}
+
+	return wildcard
+}
+
+func (r robots) crawlDelay(userAgent string) time.Duration {
+	g := r.groupFor(userAgent)
+	if g == nil {
+		return 0
+	}
+
+	return g.crawlDelay
+}
+
+// allowed применяет правило с самым длинным совпадением, при равенстве побеждает allow
+func (r robots) allowed(userAgent, path string) bool {
+	g := r.groupFor(userAgent)
+	if g == nil {
+		return true
+	}
+
+	var (
+		matched    bool
+		allow      = true
+		matchedLen = -1
+	)
+
+	for _, rule := range g.rules {
+		if !matchPath(rule.path, path) {
+			continue
+		}
+
+		switch {
+		case len(rule.path) > matchedLen:
+			matched = true
+			allow = rule.allow
+			matchedLen = len(rule.path)
+		case len(rule.path) == matchedLen && rule.allow:
+			allow = true
+		}
+	}
+
+	if !matched {
+		return true
+	}
+
+	return allow
+}
+
+// matchPath сопоставляет путь с шаблоном robots.txt, поддерживающим "*" и "$"
+func matchPath(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	if anchored {
+		pattern = strings.TrimSuffix(pattern, "$")
+	}
+
+	parts := strings.Split(pattern, "*")
+
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	pos := len(parts[0])
+
+	for i := 1; i < len(parts); i++ {
+		part := parts[i]
+
+		if i == len(parts)-1 && anchored {
+			return strings.HasSuffix(path[pos:], part)
+		}
+
+		idx := strings.Index(path[pos:], part)
+		if idx < 0 {
+			return false
+		}
+		pos += idx + len(part)
+	}
+
+	if anchored {
+		return pos == len(path)
+	}
+
+	return true
+}
@@ -8,15 +8,8 @@ import (
 	"log/slog"
 	"net/http"
 	"net/url"
-	"regexp"
-)
-
-const (
-	urlPattern = `^(https?:\/\/)?([\da-z\.-]+)\.([a-z\.]{2,6})([\/\w \.-]*)*\/?$`
-)
 
-var (
-	urlRegex = regexp.MustCompile(urlPattern)
+	"github.com/K1flar/crawlers/internal/services/link_extractor"
 )
 
 type Client interface {
@@ -85,7 +78,7 @@ func (g *Gate) Search(ctx context.Context, query string) ([]string, error) {
 
 	urls := make([]string, 0, len(dtoResponse.Res))
 	for _, source := range dtoResponse.Res {
-		if urlRegex.MatchString(source.URL) {
+		if link_extractor.Valid(source.URL) {
 			urls = append(urls, source.URL)
 		}
 	}
@@ -0,0 +1,188 @@
+package text_rank
+
+import (
+	"context"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/K1flar/crawlers/internal/services/analyzer"
+)
+
+const (
+	damping       = 0.85
+	maxIterations = 50
+	tolerance     = 1e-6
+
+	// Короткие предложения обычно навигация или подписи
+	minSentenceWords = 5
+	// Граф предложений полный, поэтому длинные тексты обрезаются
+	maxSentences = 300
+)
+
+var sentenceEnd = regexp.MustCompile(`([.!?…]+["»”)]*)\s+`)
+
+// Gate - встроенный экстрактивный пересказ: TextRank выбирает самые центральные предложения текста
+type Gate struct {
+	sentences int
+	analyzer  *analyzer.Analyzer
+}
+
+func NewGate(sentences int) *Gate {
+	return &Gate{
+		sentences: sentences,
+		analyzer:  analyzer.New(analyzer.Auto),
+	}
+}
+
+type sentence struct {
+	text  string
+	terms map[string]struct{}
+}
+
+func (g *Gate) Summarize(_ context.Context, _ string, text string) (string, error) {
+	sentences := g.split(text)
+	if len(sentences) <= g.sentences {
+		return strings.Join(texts(sentences), " "), nil
+	}
+
+	scores := rank(similarities(sentences))
+
+	order := make([]int, len(sentences))
+	for i := range order {
+		order[i] = i
+	}
+
+	slices.SortStableFunc(order, func(a, b int) int {
+		switch {
+		case scores[a] > scores[b]:
+			return -1
+		case scores[a] < scores[b]:
+			return 1
+		}
+		return 0
+	})
+
+	best := order[:g.sentences]
+	slices.Sort(best)
+
+	res := make([]string, 0, len(best))
+	for _, i := range best {
+		res = append(res, sentences[i].text)
+	}
+
+	return strings.Join(res, " "), nil
+}
+
+func (g *Gate) split(text string) []sentence {
+	res := make([]sentence, 0)
+
+	for _, line := range strings.Split(text, "\n") {
+		for _, s := range strings.Split(sentenceEnd.ReplaceAllString(line, "$1\n"), "\n") {
+			s = strings.TrimSpace(s)
+			if len(strings.Fields(s)) < minSentenceWords {
+				continue
+			}
+
+			terms := make(map[string]struct{})
+			for _, term := range g.analyzer.Analyze(s) {
+				terms[term] = struct{}{}
+			}
+
+			res = append(res, sentence{text: s, terms: terms})
+
+			if len(res) == maxSentences {
+				return res
+			}
+		}
+	}
+
+	return res
+}
+
+// similarities - мера сходства предложений из оригинальной статьи TextRank:
+// число общих терминов, деленное на сумму логарифмов длин предложений
+func similarities(sentences []sentence) [][]float64 {
+	n := len(sentences)
+
+	res := make([][]float64, n)
+	for i := range res {
+		res[i] = make([]float64, n)
+	}
+
+	for i := range n {
+		for j := i + 1; j < n; j++ {
+			a, b := sentences[i].terms, sentences[j].terms
+
+			norm := math.Log(float64(len(a))) + math.Log(float64(len(b)))
+			if norm <= 0 {
+				continue
+			}
+
+			common := 0
+			for term := range a {
+				if _, ok := b[term]; ok {
+					common++
+				}
+			}
+
+			res[i][j] = float64(common) / norm
+			res[j][i] = res[i][j]
+		}
+	}
+
+	return res
+}
+
+// rank - PageRank по взвешенному графу предложений
+func rank(weights [][]float64) []float64 {
+	n := len(weights)
+
+	outSum := make([]float64, n)
+	for i := range n {
+		for j := range n {
+			outSum[i] += weights[i][j]
+		}
+	}
+
+	scores := make([]float64, n)
+	for i := range scores {
+		scores[i] = 1 / float64(n)
+	}
+
+	next := make([]float64, n)
+
+	for range maxIterations {
+		delta := 0.0
+
+		for j := range n {
+			sum := 0.0
+			for i := range n {
+				if weights[i][j] > 0 {
+					sum += weights[i][j] / outSum[i] * scores[i]
+				}
+			}
+
+			next[j] = (1-damping)/float64(n) + damping*sum
+			delta += math.Abs(next[j] - scores[j])
+		}
+
+		scores, next = next, scores
+
+		if delta < tolerance {
+			break
+		}
+	}
+
+	return scores
+}
+
+func texts(sentences []sentence) []string {
+	res := make([]string, 0, len(sentences))
+	for _, s := range sentences {
+		res = append(res, s.text)
+	}
+
+	return res
+}
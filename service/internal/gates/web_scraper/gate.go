@@ -2,25 +2,20 @@ package web_scraper
 
 import (
 	"context"
-	"regexp"
+	net_url "net/url"
 	"strings"
 	"time"
 
 	page_models "github.com/K1flar/crawlers/internal/models/page"
+	"github.com/K1flar/crawlers/internal/services/content_extractor"
+	"github.com/K1flar/crawlers/internal/services/link_extractor"
 	"github.com/PuerkitoBio/goquery"
 	"github.com/chromedp/cdproto/network"
 	"github.com/chromedp/chromedp"
-	"github.com/samber/lo"
 )
 
 const (
 	defaultTimeout = 10 * time.Second
-
-	urlPattern = `^(https?:\/\/)?([\da-z\.-]+)\.([a-z\.]{2,6})([\/\w \.-]*)*\/?$`
-)
-
-var (
-	urlRegex = regexp.MustCompile(urlPattern)
 )
 
 type Gate struct{}
@@ -29,7 +24,9 @@ func NewGate() *Gate {
 	return &Gate{}
 }
 
-func (g *Gate) GetPage(ctx context.Context, url string) (*page_models.Page, error) {
+// GetPage загружает страницу в браузере; условные запросы не поддерживаются,
+// неизменившиеся страницы определяются по хэшу содержимого
+func (g *Gate) GetPage(ctx context.Context, url string, _ *page_models.Known) (*page_models.Page, error) {
 	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
 	defer cancel()
 
@@ -42,10 +39,7 @@ func (g *Gate) GetPage(ctx context.Context, url string) (*page_models.Page, erro
 		Status: page_models.StatusUnavailable,
 	}
 
-	var (
-		content string
-		urls    []string
-	)
+	var content string
 
 	actions := []chromedp.Action{
 		chromedp.ActionFunc(func(ctx context.Context) error {
@@ -54,6 +48,8 @@ func (g *Gate) GetPage(ctx context.Context, url string) (*page_models.Page, erro
 					if ev.Response.URL == url || strings.HasPrefix(ev.Response.URL, url) {
 						if ev.Response.Status == 200 {
 							page.Status = page_models.StatusAvailable
+							page.ETag = header(ev.Response.Headers, "ETag")
+							page.LastModified = header(ev.Response.Headers, "Last-Modified")
 						}
 					}
 				}
@@ -65,9 +61,6 @@ func (g *Gate) GetPage(ctx context.Context, url string) (*page_models.Page, erro
 		chromedp.OuterHTML("html", &content),
 		// Получаем текущий URL (может отличаться от исходного из-за редиректов)
 		chromedp.Location(&page.URL),
-		chromedp.Evaluate(`
-			Array.from(document.querySelectorAll('a')).map(a => a.href);
-		`, &urls),
 	}
 
 	err := chromedp.Run(allocCtx, actions...)
@@ -75,21 +68,30 @@ func (g *Gate) GetPage(ctx context.Context, url string) (*page_models.Page, erro
 		return nil, err
 	}
 
-	filteredURLs := lo.Filter(urls, func(url string, _ int) bool {
-		return len(url) != 0 && urlRegex.MatchString(url)
-	})
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+
+	base, _ := net_url.Parse(page.URL)
+	page.Metadata = content_extractor.ExtractMetadata(doc, base)
+	page.Links = link_extractor.Extract(doc, base)
 
-	page.URLs = filteredURLs
-	page.Content = extractText(content)
+	extracted := content_extractor.Extract(doc)
+	page.Content = extracted.Text
+	page.Headings = extracted.Headings
 
 	return page, nil
 }
 
-func extractText(html string) string {
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
-	if err != nil {
-		return html
+func header(headers network.Headers, name string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			if s, ok := v.(string); ok {
+				return s
+			}
+		}
 	}
 
-	return doc.Text()
+	return ""
 }
@@ -10,6 +10,8 @@ func ErrorMsg(err error) string {
 	switch {
 	case errors.Is(err, business_errors.InvalidQuery):
 		return "Некорректный поисковый запрос"
+	case errors.Is(err, business_errors.TaskInProcessing):
+		return "Задача обрабатывается, остановите ее или повторите позже"
 	}
 
 	return "Неизвестная ошибка, повторите позже"
@@ -0,0 +1,109 @@
+package common
+
+import (
+	"github.com/K1flar/crawlers/internal/models/task"
+	"github.com/K1flar/crawlers/internal/services/task_config"
+	"github.com/samber/lo"
+)
+
+// ConfigPatch - параметры обхода в запросах создания и изменения задачи, незаданные параметры не меняются
+type ConfigPatch struct {
+	DepthLevel             *int          `json:"depthLevel"`
+	MinWeight              *float64      `json:"minWeight"`
+	MaxSources             *int64        `json:"maxSources"`
+	MaxNeighboursForSource *int64        `json:"maxNeighboursForSource"`
+	HostRequestsPerSecond  *float64      `json:"hostRequestsPerSecond"`
+	HostBurst              *int64        `json:"hostBurst"`
+	HostMaxInFlight        *int64        `json:"hostMaxInFlight"`
+	Scorer                 *string       `json:"scorer"`
+	ScorerParams           *ScorerParams `json:"scorerParams"`
+	Language               *string       `json:"language"`
+	DuplicateDistance      *int          `json:"duplicateDistance"`
+	ScopeRules             *ScopeRules   `json:"scopeRules"`
+	Schedule               *string       `json:"schedule"`
+}
+
+type ScorerParams struct {
+	K1              *float64 `json:"k1"`
+	B               *float64 `json:"b"`
+	TitleWeight     *float64 `json:"titleWeight"`
+	BodyWeight      *float64 `json:"bodyWeight"`
+	PageRankWeight  *float64 `json:"pageRankWeight"`
+	AuthorityWeight *float64 `json:"authorityWeight"`
+}
+
+// ScopeRules в запросе заменяет правила обхода задачи целиком
+type ScopeRules struct {
+	SameDomain        bool     `json:"sameDomain"`
+	AllowHosts        []string `json:"allowHosts"`
+	DenyHosts         []string `json:"denyHosts"`
+	PathPrefixes      []string `json:"pathPrefixes"`
+	Include           []string `json:"include"`
+	Exclude           []string `json:"exclude"`
+	BlockedExtensions []string `json:"blockedExtensions"`
+}
+
+func (dto ConfigPatch) Patch() task_config.Patch {
+	patch := task_config.Patch{
+		DepthLevel:             dto.DepthLevel,
+		MinWeight:              dto.MinWeight,
+		MaxSources:             dto.MaxSources,
+		MaxNeighboursForSource: dto.MaxNeighboursForSource,
+		HostRequestsPerSecond:  dto.HostRequestsPerSecond,
+		HostBurst:              dto.HostBurst,
+		HostMaxInFlight:        dto.HostMaxInFlight,
+		Scorer:                 dto.Scorer,
+		Language:               dto.Language,
+		DuplicateDistance:      dto.DuplicateDistance,
+		Schedule:               dto.Schedule,
+	}
+
+	if dto.ScorerParams != nil {
+		patch.ScorerParams = &task.ScorerParams{
+			K1:              dto.ScorerParams.K1,
+			B:               dto.ScorerParams.B,
+			TitleWeight:     dto.ScorerParams.TitleWeight,
+			BodyWeight:      dto.ScorerParams.BodyWeight,
+			PageRankWeight:  dto.ScorerParams.PageRankWeight,
+			AuthorityWeight: dto.ScorerParams.AuthorityWeight,
+		}
+	}
+
+	if dto.ScopeRules != nil {
+		patch.ScopeRules = &task.ScopeRules{
+			SameDomain:        dto.ScopeRules.SameDomain,
+			AllowHosts:        dto.ScopeRules.AllowHosts,
+			DenyHosts:         dto.ScopeRules.DenyHosts,
+			PathPrefixes:      dto.ScopeRules.PathPrefixes,
+			Include:           dto.ScopeRules.Include,
+			Exclude:           dto.ScopeRules.Exclude,
+			BlockedExtensions: dto.ScopeRules.BlockedExtensions,
+		}
+	}
+
+	return patch
+}
+
+func NewScorerParams(params task.ScorerParams) ScorerParams {
+	return ScorerParams{
+		K1:              params.K1,
+		B:               params.B,
+		TitleWeight:     params.TitleWeight,
+		BodyWeight:      params.BodyWeight,
+		PageRankWeight:  params.PageRankWeight,
+		AuthorityWeight: params.AuthorityWeight,
+	}
+}
+
+// NewScopeRules отдает пустые списки вместо null
+func NewScopeRules(rules task.ScopeRules) ScopeRules {
+	return ScopeRules{
+		SameDomain:        rules.SameDomain,
+		AllowHosts:        lo.CoalesceSliceOrEmpty(rules.AllowHosts),
+		DenyHosts:         lo.CoalesceSliceOrEmpty(rules.DenyHosts),
+		PathPrefixes:      lo.CoalesceSliceOrEmpty(rules.PathPrefixes),
+		Include:           lo.CoalesceSliceOrEmpty(rules.Include),
+		Exclude:           lo.CoalesceSliceOrEmpty(rules.Exclude),
+		BlockedExtensions: lo.CoalesceSliceOrEmpty(rules.BlockedExtensions),
+	}
+}
@@ -0,0 +1,105 @@
+package compare_launches
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/K1flar/crawlers/internal/handlers/common"
+	"github.com/K1flar/crawlers/internal/models/source"
+	"github.com/K1flar/crawlers/internal/stories"
+	"github.com/samber/lo"
+)
+
+type Handler struct {
+	log   *slog.Logger
+	story stories.CompareLaunches
+}
+
+func New(
+	log *slog.Logger,
+	story stories.CompareLaunches,
+) *Handler {
+	return &Handler{log, story}
+}
+
+type dtoRequest struct {
+	TaskID     int64 `json:"taskId"`
+	FromLaunch int64 `json:"fromLaunch"`
+	ToLaunch   int64 `json:"toLaunch"`
+}
+
+type dtoResponse struct {
+	FromLaunchID int64           `json:"fromLaunchId"`
+	ToLaunchID   int64           `json:"toLaunchId"`
+	Added        []dtoSource     `json:"added"`
+	Removed      []dtoSource     `json:"removed"`
+	Kept         []dtoKeptSource `json:"kept"`
+}
+
+type dtoSource struct {
+	ID       int64   `json:"id"`
+	Title    string  `json:"title"`
+	URL      string  `json:"url"`
+	Weight   float64 `json:"weight"`
+	Scorer   string  `json:"scorer"`
+	ParentID *int64  `json:"parentId"`
+}
+
+type dtoKeptSource struct {
+	dtoSource
+	PrevWeight   float64 `json:"prevWeight"`
+	WeightDelta  float64 `json:"weightDelta"`
+	PrevParentID *int64  `json:"prevParentId"`
+	Reparented   bool    `json:"reparented"`
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			h.log.Error(err.Error())
+		}
+	}()
+
+	dto, err := common.DTO[dtoRequest](r)
+	if err != nil {
+		common.BadRequest(w, "bad request body")
+		return
+	}
+
+	comparison, err := h.story.Compare(ctx, dto.TaskID, dto.FromLaunch, dto.ToLaunch)
+	if err != nil {
+		common.Error(w, err)
+		return
+	}
+
+	common.OK(w, dtoResponse{
+		FromLaunchID: comparison.From.ID,
+		ToLaunchID:   comparison.To.ID,
+		Added:        lo.Map(comparison.Added, mapSource),
+		Removed:      lo.Map(comparison.Removed, mapSource),
+		Kept: lo.Map(comparison.Kept, func(s source.Kept, _ int) dtoKeptSource {
+			return dtoKeptSource{
+				dtoSource:    mapSource(s.ForTask, 0),
+				PrevWeight:   s.PrevWeight,
+				WeightDelta:  s.WeightDelta(),
+				PrevParentID: s.PrevParentID,
+				Reparented:   s.Reparented(),
+			}
+		}),
+	})
+}
+
+func mapSource(s source.ForTask, _ int) dtoSource {
+	return dtoSource{
+		ID:       s.ID,
+		Title:    s.Title,
+		URL:      s.URL,
+		Weight:   s.Weight,
+		Scorer:   s.Scorer,
+		ParentID: s.ParentID,
+	}
+}
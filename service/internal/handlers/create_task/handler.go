@@ -7,6 +7,7 @@ import (
 
 	"github.com/K1flar/crawlers/internal/business_errors"
 	"github.com/K1flar/crawlers/internal/handlers/common"
+	"github.com/K1flar/crawlers/internal/services/task_config"
 	"github.com/K1flar/crawlers/internal/stories"
 )
 
@@ -22,8 +23,10 @@ func New(
 	return &Handler{log, story}
 }
 
+// dtoRequest - запрос и параметры обхода, незаданные параметры берутся по умолчанию
 type dtoRequest struct {
 	Query string `json:"query"`
+	common.ConfigPatch
 }
 
 type dtoResponse struct {
@@ -45,8 +48,14 @@ func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id, err := h.story.Create(r.Context(), dto.Query)
+	id, err := h.story.Create(r.Context(), dto.Query, dto.Patch())
 	if err != nil {
+		var configError *task_config.Error
+		if errors.As(err, &configError) {
+			common.BadRequest(w, configError.Error())
+			return
+		}
+
 		var businessError *business_errors.BusinessError
 		if errors.As(err, &businessError) {
 			common.Forbidden(w, businessError.Code, common.ErrorMsg(err))
@@ -0,0 +1,53 @@
+package delete_task
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/K1flar/crawlers/internal/handlers/common"
+	"github.com/K1flar/crawlers/internal/stories"
+)
+
+type Handler struct {
+	log   *slog.Logger
+	story stories.DeleteTask
+}
+
+func New(
+	log *slog.Logger,
+	story stories.DeleteTask,
+) *Handler {
+	return &Handler{log, story}
+}
+
+type dtoRequest struct {
+	ID int64 `json:"id"`
+	// Удалить задачу, даже если она обрабатывается, прервав обход
+	Force bool `json:"force"`
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			h.log.Error(err.Error())
+		}
+	}()
+
+	dto, err := common.DTO[dtoRequest](r)
+	if err != nil {
+		common.BadRequest(w, "bad request body")
+		return
+	}
+
+	err = h.story.Delete(ctx, dto.ID, dto.Force)
+	if err != nil {
+		common.Error(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,84 @@
+package export_task
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var csvHeader = []string{
+	"id", "parent_id", "url", "title", "weight", "scorer",
+	"pagerank", "hub", "authority",
+	"anchor", "rel", "aliases",
+	"description", "language", "author", "site_name", "published_at", "modified_at",
+}
+
+// writeCSV выгружает плоскую таблицу источников, дерево восстанавливается по parent_id
+func (h *Handler) writeCSV(_ context.Context, w io.Writer, kb knowledgeBase) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, s := range kb.sources {
+		link, _ := kb.parentLink(s)
+
+		parentID := ""
+		if s.ParentID != nil {
+			parentID = strconv.FormatInt(*s.ParentID, 10)
+		}
+
+		err := cw.Write([]string{
+			strconv.FormatInt(s.ID, 10),
+			parentID,
+			escapeCell(s.URL),
+			escapeCell(s.Title),
+			formatFloat(s.Weight),
+			escapeCell(s.Scorer),
+			formatFloat(s.Graph.PageRank),
+			formatFloat(s.Graph.Hub),
+			formatFloat(s.Graph.Authority),
+			escapeCell(link.Anchor),
+			escapeCell(strings.Join(link.Rel, " ")),
+			escapeCell(strings.Join(kb.aliases[s.ID], " ")),
+			escapeCell(s.Metadata.Description),
+			escapeCell(s.Metadata.Language),
+			escapeCell(s.Metadata.Author),
+			escapeCell(s.Metadata.SiteName),
+			formatTime(s.Metadata.PublishedAt),
+			formatTime(s.Metadata.ModifiedAt),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// escapeCell экранирует текст страниц, который табличный редактор принял бы за формулу
+func escapeCell(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+
+	return v
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
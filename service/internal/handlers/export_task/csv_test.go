@@ -0,0 +1,25 @@
+package export_task
+
+import "testing"
+
+func TestEscapeCell(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "", want: ""},
+		{in: "Заголовок", want: "Заголовок"},
+		{in: "https://example.com/=a", want: "https://example.com/=a"},
+		{in: "=HYPERLINK(\"https://evil.example\")", want: "'=HYPERLINK(\"https://evil.example\")"},
+		{in: "+1", want: "'+1"},
+		{in: "-2+3", want: "'-2+3"},
+		{in: "@SUM(A1:A2)", want: "'@SUM(A1:A2)"},
+		{in: "\t=1", want: "'\t=1"},
+	}
+
+	for _, tt := range tests {
+		if got := escapeCell(tt.in); got != tt.want {
+			t.Errorf("escapeCell(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package export_task
+
+import (
+	"bufio"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const graphMLNamespace = "http://graphml.graphdrawing.org/xmlns"
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+var graphMLKeys = []graphMLKey{
+	{ID: "url", For: "node", Name: "url", Type: "string"},
+	{ID: "title", For: "node", Name: "title", Type: "string"},
+	{ID: "weight", For: "node", Name: "weight", Type: "double"},
+	{ID: "pagerank", For: "node", Name: "pagerank", Type: "double"},
+	{ID: "authority", For: "node", Name: "authority", Type: "double"},
+	{ID: "anchor", For: "edge", Name: "anchor", Type: "string"},
+}
+
+// writeGraphML выгружает дерево источников: ребро ведет от родителя к найденному на нем источнику
+func (h *Handler) writeGraphML(_ context.Context, w io.Writer, kb knowledgeBase) error {
+	graph := graphMLGraph{
+		ID:          fmt.Sprintf("task-%d-launch-%d", kb.task.ID, kb.launch.Number),
+		EdgeDefault: "directed",
+	}
+
+	for _, s := range kb.sources {
+		graph.Nodes = append(graph.Nodes, graphMLNode{
+			ID: nodeID(s.ID),
+			Data: []graphMLData{
+				{Key: "url", Value: s.URL},
+				{Key: "title", Value: s.Title},
+				{Key: "weight", Value: formatFloat(s.Weight)},
+				{Key: "pagerank", Value: formatFloat(s.Graph.PageRank)},
+				{Key: "authority", Value: formatFloat(s.Graph.Authority)},
+			},
+		})
+	}
+
+	for _, e := range treeEdges(kb) {
+		graph.Edges = append(graph.Edges, graphMLEdge{
+			Source: nodeID(e.from),
+			Target: nodeID(e.to),
+			Data:   []graphMLData{{Key: "anchor", Value: e.anchor}},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	if err := enc.Encode(graphML{XMLNS: graphMLNamespace, Keys: graphMLKeys, Graph: graph}); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
+
+// writeDOT выгружает дерево источников в формате Graphviz
+func (h *Handler) writeDOT(_ context.Context, w io.Writer, kb knowledgeBase) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "digraph %s {\n", dotQuote(fmt.Sprintf("task-%d-launch-%d", kb.task.ID, kb.launch.Number)))
+	fmt.Fprint(bw, "  node [shape=box];\n")
+
+	for _, s := range kb.sources {
+		fmt.Fprintf(bw, "  %s [label=%s, URL=%s, tooltip=%s];\n",
+			dotQuote(nodeID(s.ID)),
+			dotQuote(fmt.Sprintf("%s\n%s", s.Title, formatFloat(s.Weight))),
+			dotQuote(s.URL),
+			dotQuote(s.URL),
+		)
+	}
+
+	for _, e := range treeEdges(kb) {
+		fmt.Fprintf(bw, "  %s -> %s [label=%s];\n", dotQuote(nodeID(e.from)), dotQuote(nodeID(e.to)), dotQuote(e.anchor))
+	}
+
+	fmt.Fprint(bw, "}\n")
+
+	return bw.Flush()
+}
+
+type treeEdge struct {
+	from   int64
+	to     int64
+	anchor string
+}
+
+// treeEdges возвращает ребра дерева, родитель которых есть среди источников запуска
+func treeEdges(kb knowledgeBase) []treeEdge {
+	ids := make(map[int64]struct{}, len(kb.sources))
+	for _, s := range kb.sources {
+		ids[s.ID] = struct{}{}
+	}
+
+	var edges []treeEdge
+	for _, s := range kb.sources {
+		if s.ParentID == nil || *s.ParentID == s.ID {
+			continue
+		}
+
+		if _, ok := ids[*s.ParentID]; !ok {
+			continue
+		}
+
+		link, _ := kb.parentLink(s)
+
+		edges = append(edges, treeEdge{from: *s.ParentID, to: s.ID, anchor: link.Anchor})
+	}
+
+	return edges
+}
+
+func nodeID(id int64) string {
+	return "n" + strconv.FormatInt(id, 10)
+}
+
+var dotReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r", "", "\n", `\n`)
+
+func dotQuote(s string) string {
+	return `"` + dotReplacer.Replace(s) + `"`
+}
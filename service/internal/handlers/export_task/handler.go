@@ -0,0 +1,200 @@
+package export_task
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/K1flar/crawlers/internal/handlers/common"
+	"github.com/K1flar/crawlers/internal/models/launch"
+	"github.com/K1flar/crawlers/internal/models/source"
+	"github.com/K1flar/crawlers/internal/models/task"
+	"github.com/K1flar/crawlers/internal/storage"
+	"github.com/samber/lo"
+)
+
+type Handler struct {
+	log            *slog.Logger
+	tasks          storage.Tasks
+	launches       storage.Launches
+	sources        storage.Sources
+	sourceAliases  storage.SourceAliases
+	sourceLinks    storage.SourceLinks
+	sourceContents storage.SourceContents
+}
+
+func New(
+	log *slog.Logger,
+	tasks storage.Tasks,
+	launches storage.Launches,
+	sources storage.Sources,
+	sourceAliases storage.SourceAliases,
+	sourceLinks storage.SourceLinks,
+	sourceContents storage.SourceContents,
+) *Handler {
+	return &Handler{log, tasks, launches, sources, sourceAliases, sourceLinks, sourceContents}
+}
+
+type format string
+
+const (
+	formatJSON    format = "json"
+	formatCSV     format = "csv"
+	formatNDJSON  format = "ndjson"
+	formatGraphML format = "graphml"
+	formatDOT     format = "dot"
+)
+
+type exporter struct {
+	contentType string
+	extension   string
+	write       func(h *Handler, ctx context.Context, w io.Writer, kb knowledgeBase) error
+}
+
+var exporters = map[format]exporter{
+	formatJSON:    {"application/json", "json", (*Handler).writeJSON},
+	formatCSV:     {"text/csv; charset=utf-8", "csv", (*Handler).writeCSV},
+	formatNDJSON:  {"application/x-ndjson", "ndjson", (*Handler).writeNDJSON},
+	formatGraphML: {"application/graphml+xml", "graphml", (*Handler).writeGraphML},
+	formatDOT:     {"text/vnd.graphviz", "dot", (*Handler).writeDOT},
+}
+
+// knowledgeBase - источники запуска задачи, отсортированные по убыванию веса
+type knowledgeBase struct {
+	task       task.Task
+	launch     launch.Launch
+	sources    []source.ForTask
+	aliases    map[int64][]string
+	links      []source.Link
+	linkByEdge map[[2]int64]source.Link
+}
+
+// parentLink возвращает ссылку с родителя, по которой найден источник
+func (kb knowledgeBase) parentLink(s source.ForTask) (source.Link, bool) {
+	if s.ParentID == nil {
+		return source.Link{}, false
+	}
+
+	link, ok := kb.linkByEdge[[2]int64{*s.ParentID, s.ID}]
+
+	return link, ok
+}
+
+// GET /tasks/{id}/export?format=json|csv|ndjson|graphml|dot&launch=<номер запуска>
+// Без номера выгружается последний запуск
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			h.log.Error(err.Error())
+		}
+	}()
+
+	taskID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		common.BadRequest(w, "bad task id")
+		return
+	}
+
+	f := format(r.URL.Query().Get("format"))
+	if f == "" {
+		f = formatJSON
+	}
+
+	exp, ok := exporters[f]
+	if !ok {
+		common.BadRequest(w, "unknown export format")
+		return
+	}
+
+	var launchNumber *int64
+	if v := r.URL.Query().Get("launch"); v != "" {
+		number, parseErr := strconv.ParseInt(v, 10, 64)
+		if parseErr != nil {
+			err = parseErr
+			common.BadRequest(w, "bad launch number")
+			return
+		}
+
+		launchNumber = &number
+	}
+
+	kb, err := h.load(ctx, taskID, launchNumber)
+	if err != nil {
+		common.Error(w, err)
+		return
+	}
+
+	filename := fmt.Sprintf("task-%d-launch-%d.%s", kb.task.ID, kb.launch.Number, exp.extension)
+
+	w.Header().Set("Content-Type", exp.contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	w.WriteHeader(http.StatusOK)
+
+	// Заголовки уже отправлены, ошибку можно только залогировать
+	if err = exp.write(h, ctx, w, kb); err != nil {
+		err = fmt.Errorf("failed to export task [%d] as %s: %w", taskID, f, err)
+	}
+}
+
+func (h *Handler) load(ctx context.Context, taskID int64, launchNumber *int64) (knowledgeBase, error) {
+	t, err := h.tasks.GetByID(ctx, taskID)
+	if err != nil {
+		return knowledgeBase{}, err
+	}
+
+	var l launch.Launch
+	if launchNumber != nil {
+		l, err = h.launches.GetByNumber(ctx, taskID, *launchNumber)
+	} else {
+		l, err = h.launches.GetLastByTaskID(ctx, taskID)
+	}
+	if err != nil {
+		return knowledgeBase{}, err
+	}
+
+	sources, err := h.sources.GetByLaunchID(ctx, l.ID)
+	if err != nil {
+		return knowledgeBase{}, err
+	}
+
+	sort.SliceStable(sources, func(i, j int) bool {
+		if sources[i].Weight != sources[j].Weight {
+			return sources[i].Weight > sources[j].Weight
+		}
+
+		return sources[i].ID < sources[j].ID
+	})
+
+	aliases, err := h.sourceAliases.GetByLaunchID(ctx, l.ID)
+	if err != nil {
+		return knowledgeBase{}, err
+	}
+
+	links, err := h.sourceLinks.GetByLaunchID(ctx, l.ID)
+	if err != nil {
+		return knowledgeBase{}, err
+	}
+
+	return knowledgeBase{
+		task:    t,
+		launch:  l,
+		sources: sources,
+		aliases: aliases,
+		links:   links,
+		// Ссылки на страницы вне базы знаний не связывают источники
+		linkByEdge: lo.KeyBy(lo.Filter(links, func(link source.Link, _ int) bool {
+			return link.ToSourceID != nil
+		}), func(link source.Link) [2]int64 {
+			return [2]int64{link.FromSourceID, *link.ToSourceID}
+		}),
+	}, nil
+}
@@ -0,0 +1,153 @@
+package export_task
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/K1flar/crawlers/internal/models/page"
+	"github.com/K1flar/crawlers/internal/models/source"
+	"github.com/samber/lo"
+)
+
+type dtoKnowledgeBase struct {
+	TaskID  int64      `json:"taskId"`
+	Query   string     `json:"query"`
+	Launch  dtoLaunch  `json:"launch"`
+	Sources []*dtoNode `json:"sources"`
+}
+
+type dtoLaunch struct {
+	ID         int64      `json:"id"`
+	Number     int64      `json:"number"`
+	Status     string     `json:"status"`
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt"`
+}
+
+type dtoSource struct {
+	ID       int64       `json:"id"`
+	ParentID *int64      `json:"parentId"`
+	Title    string      `json:"title"`
+	URL      string      `json:"url"`
+	Weight   float64     `json:"weight"`
+	Scorer   string      `json:"scorer"`
+	Metadata dtoMetadata `json:"metadata"`
+	Aliases  []string    `json:"aliases"`
+	Link     *dtoLink    `json:"link"`
+	Graph    dtoGraph    `json:"graph"`
+}
+
+// dtoNode - источник вместе с найденными на нем дочерними источниками
+type dtoNode struct {
+	dtoSource
+	Children []*dtoNode `json:"children"`
+}
+
+type dtoGraph struct {
+	PageRank  float64 `json:"pageRank"`
+	Hub       float64 `json:"hub"`
+	Authority float64 `json:"authority"`
+}
+
+type dtoLink struct {
+	Anchor  string   `json:"anchor"`
+	Rel     []string `json:"rel"`
+	Snippet string   `json:"snippet"`
+}
+
+type dtoMetadata struct {
+	Description string     `json:"description"`
+	Language    string     `json:"language"`
+	Canonical   string     `json:"canonical"`
+	Author      string     `json:"author"`
+	SiteName    string     `json:"siteName"`
+	Image       string     `json:"image"`
+	PublishedAt *time.Time `json:"publishedAt"`
+	ModifiedAt  *time.Time `json:"modifiedAt"`
+}
+
+// writeJSON выгружает базу знаний деревом: корни - стартовые источники и источники,
+// родитель которых не попал в запуск
+func (h *Handler) writeJSON(_ context.Context, w io.Writer, kb knowledgeBase) error {
+	nodes := make(map[int64]*dtoNode, len(kb.sources))
+	for _, s := range kb.sources {
+		nodes[s.ID] = &dtoNode{
+			dtoSource: mapSource(kb, s),
+			Children:  []*dtoNode{},
+		}
+	}
+
+	roots := make([]*dtoNode, 0)
+	for _, s := range kb.sources {
+		node := nodes[s.ID]
+
+		if s.ParentID == nil || *s.ParentID == s.ID {
+			roots = append(roots, node)
+			continue
+		}
+
+		parent, ok := nodes[*s.ParentID]
+		if !ok {
+			roots = append(roots, node)
+			continue
+		}
+
+		parent.Children = append(parent.Children, node)
+	}
+
+	return json.NewEncoder(w).Encode(dtoKnowledgeBase{
+		TaskID: kb.task.ID,
+		Query:  kb.task.Query,
+		Launch: dtoLaunch{
+			ID:         kb.launch.ID,
+			Number:     kb.launch.Number,
+			Status:     string(kb.launch.Status),
+			StartedAt:  kb.launch.StartedAt,
+			FinishedAt: kb.launch.FinishedAt,
+		},
+		Sources: roots,
+	})
+}
+
+func mapSource(kb knowledgeBase, s source.ForTask) dtoSource {
+	res := dtoSource{
+		ID:       s.ID,
+		ParentID: s.ParentID,
+		Title:    s.Title,
+		URL:      s.URL,
+		Weight:   s.Weight,
+		Scorer:   s.Scorer,
+		Metadata: mapMetadata(s.Metadata),
+		Aliases:  lo.CoalesceSliceOrEmpty(kb.aliases[s.ID]),
+		Graph: dtoGraph{
+			PageRank:  s.Graph.PageRank,
+			Hub:       s.Graph.Hub,
+			Authority: s.Graph.Authority,
+		},
+	}
+
+	if link, ok := kb.parentLink(s); ok {
+		res.Link = &dtoLink{
+			Anchor:  link.Anchor,
+			Rel:     lo.CoalesceSliceOrEmpty(link.Rel),
+			Snippet: link.Snippet,
+		}
+	}
+
+	return res
+}
+
+func mapMetadata(m page.Metadata) dtoMetadata {
+	return dtoMetadata{
+		Description: m.Description,
+		Language:    m.Language,
+		Canonical:   m.Canonical,
+		Author:      m.Author,
+		SiteName:    m.SiteName,
+		Image:       m.Image,
+		PublishedAt: m.PublishedAt,
+		ModifiedAt:  m.ModifiedAt,
+	}
+}
@@ -0,0 +1,46 @@
+package export_task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/K1flar/crawlers/internal/business_errors"
+)
+
+type dtoRecord struct {
+	dtoSource
+	Content     string `json:"content"`
+	ContentHash string `json:"contentHash"`
+}
+
+// writeNDJSON выгружает по строке на источник вместе с текстом страницы на момент запуска.
+// Тексты читаются по одному, чтобы не держать в памяти всю базу знаний
+func (h *Handler) writeNDJSON(ctx context.Context, w io.Writer, kb knowledgeBase) error {
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+
+	for _, s := range kb.sources {
+		record := dtoRecord{dtoSource: mapSource(kb, s)}
+
+		content, err := h.sourceContents.Get(ctx, s.ID, &kb.launch.ID)
+		if err != nil && !errors.Is(err, business_errors.EntityNotFound) {
+			return err
+		}
+
+		record.Content = content.Text
+		record.ContentHash = content.Hash
+
+		if err := enc.Encode(record); err != nil {
+			return err
+		}
+
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	return nil
+}
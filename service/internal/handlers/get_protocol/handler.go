@@ -8,6 +8,7 @@ import (
 
 	"github.com/K1flar/crawlers/internal/handlers/common"
 	"github.com/K1flar/crawlers/internal/models/launch"
+	"github.com/K1flar/crawlers/internal/models/page"
 	"github.com/K1flar/crawlers/internal/models/source"
 	"github.com/K1flar/crawlers/internal/storage"
 	"github.com/K1flar/crawlers/internal/utils"
@@ -55,6 +56,18 @@ type dtoProtocolItem struct {
 	Duration       *time.Duration `json:"duration"`
 	LaunchStatus   string         `json:"launchStatus"`
 	LaunchErrorMsg *string        `json:"launchErrorMsg"`
+	Metadata       dtoMetadata    `json:"metadata"`
+}
+
+type dtoMetadata struct {
+	Description string     `json:"description"`
+	Language    string     `json:"language"`
+	Canonical   string     `json:"canonical"`
+	Author      string     `json:"author"`
+	SiteName    string     `json:"siteName"`
+	Image       string     `json:"image"`
+	PublishedAt *time.Time `json:"publishedAt"`
+	ModifiedAt  *time.Time `json:"modifiedAt"`
 }
 
 func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
@@ -115,7 +128,21 @@ func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
 				Duration:       s.Duration,
 				LaunchStatus:   string(s.LaunchStatus),
 				LaunchErrorMsg: common.ErrorSlugToMsg((*launch.ErrorSlug)(s.LaunchErrorSlug)),
+				Metadata:       mapMetadata(s.Metadata),
 			}
 		}),
 	})
 }
+
+func mapMetadata(m page.Metadata) dtoMetadata {
+	return dtoMetadata{
+		Description: m.Description,
+		Language:    m.Language,
+		Canonical:   m.Canonical,
+		Author:      m.Author,
+		SiteName:    m.SiteName,
+		Image:       m.Image,
+		PublishedAt: m.PublishedAt,
+		ModifiedAt:  m.ModifiedAt,
+	}
+}
@@ -0,0 +1,67 @@
+package get_source_content
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/K1flar/crawlers/internal/handlers/common"
+	"github.com/K1flar/crawlers/internal/storage"
+)
+
+type Handler struct {
+	log            *slog.Logger
+	sourceContents storage.SourceContents
+}
+
+func New(
+	log *slog.Logger,
+	sourceContents storage.SourceContents,
+) *Handler {
+	return &Handler{log, sourceContents}
+}
+
+type dtoRequest struct {
+	SourceID int64  `json:"sourceId"`
+	LaunchID *int64 `json:"launchId"`
+}
+
+type dtoResponse struct {
+	SourceID    int64     `json:"sourceId"`
+	LaunchID    int64     `json:"launchId"`
+	Content     string    `json:"content"`
+	ContentHash string    `json:"contentHash"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			h.log.Error(err.Error())
+		}
+	}()
+
+	dto, err := common.DTO[dtoRequest](r)
+	if err != nil {
+		common.BadRequest(w, "bad request body")
+		return
+	}
+
+	content, err := h.sourceContents.Get(ctx, dto.SourceID, dto.LaunchID)
+	if err != nil {
+		common.Error(w, err)
+		return
+	}
+
+	common.OK(w, dtoResponse{
+		SourceID:    content.SourceID,
+		LaunchID:    content.LaunchID,
+		Content:     content.Text,
+		ContentHash: content.Hash,
+		CreatedAt:   content.CreatedAt,
+	})
+}
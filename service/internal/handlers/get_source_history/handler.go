@@ -0,0 +1,90 @@
+package get_source_history
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/K1flar/crawlers/internal/handlers/common"
+	"github.com/K1flar/crawlers/internal/models/source"
+	"github.com/K1flar/crawlers/internal/stories"
+	"github.com/samber/lo"
+)
+
+type Handler struct {
+	log   *slog.Logger
+	story stories.GetSourceHistory
+}
+
+func New(
+	log *slog.Logger,
+	story stories.GetSourceHistory,
+) *Handler {
+	return &Handler{log, story}
+}
+
+type dtoRequest struct {
+	SourceID    int64  `json:"sourceId"`
+	FromVersion *int64 `json:"fromVersion"`
+	ToVersion   *int64 `json:"toVersion"`
+}
+
+type dtoVersion struct {
+	Version     int64     `json:"version"`
+	LaunchID    *int64    `json:"launchId"`
+	Title       string    `json:"title"`
+	Status      string    `json:"status"`
+	ContentHash string    `json:"contentHash"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type dtoResponse struct {
+	SourceID    int64        `json:"sourceId"`
+	Versions    []dtoVersion `json:"versions"`
+	FromVersion int64        `json:"fromVersion"`
+	ToVersion   int64        `json:"toVersion"`
+	TitleDiff   string       `json:"titleDiff"`
+	ContentDiff string       `json:"contentDiff"`
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			h.log.Error(err.Error())
+		}
+	}()
+
+	dto, err := common.DTO[dtoRequest](r)
+	if err != nil {
+		common.BadRequest(w, "bad request body")
+		return
+	}
+
+	history, err := h.story.Get(ctx, dto.SourceID, dto.FromVersion, dto.ToVersion)
+	if err != nil {
+		common.Error(w, err)
+		return
+	}
+
+	common.OK(w, dtoResponse{
+		SourceID: dto.SourceID,
+		Versions: lo.Map(history.Versions, func(v source.Version, _ int) dtoVersion {
+			return dtoVersion{
+				Version:     v.Version,
+				LaunchID:    v.LaunchID,
+				Title:       v.Title,
+				Status:      string(v.Status),
+				ContentHash: v.ContentHash,
+				CreatedAt:   v.CreatedAt,
+			}
+		}),
+		FromVersion: history.From.Version,
+		ToVersion:   history.To.Version,
+		TitleDiff:   history.TitleDiff,
+		ContentDiff: history.ContentDiff,
+	})
+}
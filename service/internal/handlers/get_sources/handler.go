@@ -3,23 +3,29 @@ package get_sources
 import (
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/K1flar/crawlers/internal/handlers/common"
+	"github.com/K1flar/crawlers/internal/models/page"
 	"github.com/K1flar/crawlers/internal/models/source"
 	"github.com/K1flar/crawlers/internal/storage"
 	"github.com/samber/lo"
 )
 
 type Handler struct {
-	log     *slog.Logger
-	sources storage.Sources
+	log           *slog.Logger
+	sources       storage.Sources
+	sourceAliases storage.SourceAliases
+	sourceLinks   storage.SourceLinks
 }
 
 func New(
 	log *slog.Logger,
 	sources storage.Sources,
+	sourceAliases storage.SourceAliases,
+	sourceLinks storage.SourceLinks,
 ) *Handler {
-	return &Handler{log, sources}
+	return &Handler{log, sources, sourceAliases, sourceLinks}
 }
 
 type dtoRequest struct {
@@ -31,11 +37,39 @@ type dtoResponse struct {
 }
 
 type dtoSource struct {
-	ID       int64   `json:"id"`
-	Title    string  `json:"title"`
-	URL      string  `json:"url"`
-	Weight   float64 `json:"weight"`
-	ParentID *int64  `json:"parentId"`
+	ID       int64       `json:"id"`
+	Title    string      `json:"title"`
+	URL      string      `json:"url"`
+	Weight   float64     `json:"weight"`
+	Scorer   string      `json:"scorer"`
+	ParentID *int64      `json:"parentId"`
+	Metadata dtoMetadata `json:"metadata"`
+	Aliases  []string    `json:"aliases"`
+	Link     *dtoLink    `json:"link"` // ссылка с родителя, по которой найден источник
+	Graph    dtoGraph    `json:"graph"`
+}
+
+type dtoGraph struct {
+	PageRank  float64 `json:"pageRank"`
+	Hub       float64 `json:"hub"`
+	Authority float64 `json:"authority"`
+}
+
+type dtoLink struct {
+	Anchor  string   `json:"anchor"`
+	Rel     []string `json:"rel"`
+	Snippet string   `json:"snippet"`
+}
+
+type dtoMetadata struct {
+	Description string     `json:"description"`
+	Language    string     `json:"language"`
+	Canonical   string     `json:"canonical"`
+	Author      string     `json:"author"`
+	SiteName    string     `json:"siteName"`
+	Image       string     `json:"image"`
+	PublishedAt *time.Time `json:"publishedAt"`
+	ModifiedAt  *time.Time `json:"modifiedAt"`
 }
 
 func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
@@ -61,6 +95,25 @@ func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	aliases, err := h.sourceAliases.GetLastByTaskID(ctx, dto.ID)
+	if err != nil {
+		common.Error(w, err)
+		return
+	}
+
+	links, err := h.sourceLinks.GetLastByTaskID(ctx, dto.ID)
+	if err != nil {
+		common.Error(w, err)
+		return
+	}
+
+	// Ссылки на страницы вне базы знаний не связывают источники
+	linkByEdge := lo.KeyBy(lo.Filter(links, func(link source.Link, _ int) bool {
+		return link.ToSourceID != nil
+	}), func(link source.Link) [2]int64 {
+		return [2]int64{link.FromSourceID, *link.ToSourceID}
+	})
+
 	common.OK(w, dtoResponse{
 		Sources: lo.Map(sources, func(source source.ForTask, _ int) dtoSource {
 			return dtoSource{
@@ -68,8 +121,47 @@ func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
 				URL:      source.URL,
 				Title:    source.Title,
 				Weight:   source.Weight,
+				Scorer:   source.Scorer,
 				ParentID: source.ParentID,
+				Metadata: mapMetadata(source.Metadata),
+				Aliases:  lo.CoalesceSliceOrEmpty(aliases[source.ID]),
+				Link:     mapLink(source, linkByEdge),
+				Graph: dtoGraph{
+					PageRank:  source.Graph.PageRank,
+					Hub:       source.Graph.Hub,
+					Authority: source.Graph.Authority,
+				},
 			}
 		}),
 	})
 }
+
+func mapMetadata(m page.Metadata) dtoMetadata {
+	return dtoMetadata{
+		Description: m.Description,
+		Language:    m.Language,
+		Canonical:   m.Canonical,
+		Author:      m.Author,
+		SiteName:    m.SiteName,
+		Image:       m.Image,
+		PublishedAt: m.PublishedAt,
+		ModifiedAt:  m.ModifiedAt,
+	}
+}
+
+func mapLink(s source.ForTask, linkByEdge map[[2]int64]source.Link) *dtoLink {
+	if s.ParentID == nil {
+		return nil
+	}
+
+	link, ok := linkByEdge[[2]int64{*s.ParentID, s.ID}]
+	if !ok {
+		return nil
+	}
+
+	return &dtoLink{
+		Anchor:  link.Anchor,
+		Rel:     lo.CoalesceSliceOrEmpty(link.Rel),
+		Snippet: link.Snippet,
+	}
+}
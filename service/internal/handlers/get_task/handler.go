@@ -6,9 +6,11 @@ import (
 	"time"
 
 	"github.com/K1flar/crawlers/internal/handlers/common"
+	"github.com/K1flar/crawlers/internal/models/page"
 	task_model "github.com/K1flar/crawlers/internal/models/task"
 	"github.com/K1flar/crawlers/internal/storage"
 	"github.com/K1flar/crawlers/internal/utils"
+	"github.com/samber/lo"
 )
 
 type Handler struct {
@@ -30,18 +32,40 @@ type dtoRequest struct {
 }
 
 type dtoResponse struct {
-	Query                  string         `json:"query"`
-	Status                 string         `json:"status"`
-	CreatedAt              time.Time      `json:"createdAt"`
-	UpdatedAt              time.Time      `json:"updatedAt"`
-	ProcessedAt            *time.Time     `json:"processedAt"`
-	SourcesViewed          *int64         `json:"sourcesViewed"`
-	LaunchDuration         *time.Duration `json:"launchDuration"`
-	ErrorMsg               *string        `json:"errorMsg"`
-	DepthLevel             int            `json:"depthLevel"`
-	MinWeight              float64        `json:"minWeight"`
-	MaxSources             int64          `json:"maxSources"`
-	MaxNeighboursForSource int64          `json:"maxNeighboursForSource"`
+	Query                  string              `json:"query"`
+	Status                 string              `json:"status"`
+	CreatedAt              time.Time           `json:"createdAt"`
+	UpdatedAt              time.Time           `json:"updatedAt"`
+	ProcessedAt            *time.Time          `json:"processedAt"`
+	SourcesViewed          *int64              `json:"sourcesViewed"`
+	SourcesNew             *int64              `json:"sourcesNew"`
+	SourcesChanged         *int64              `json:"sourcesChanged"`
+	SourcesUnchanged       *int64              `json:"sourcesUnchanged"`
+	SourcesGone            *int64              `json:"sourcesGone"`
+	Skipped                map[string]int64    `json:"skipped"`
+	LaunchDuration         *time.Duration      `json:"launchDuration"`
+	ErrorMsg               *string             `json:"errorMsg"`
+	DepthLevel             int                 `json:"depthLevel"`
+	MinWeight              float64             `json:"minWeight"`
+	MaxSources             int64               `json:"maxSources"`
+	MaxNeighboursForSource int64               `json:"maxNeighboursForSource"`
+	HostRequestsPerSecond  float64             `json:"hostRequestsPerSecond"`
+	HostBurst              int64               `json:"hostBurst"`
+	HostMaxInFlight        int64               `json:"hostMaxInFlight"`
+	Scorer                 string              `json:"scorer"`
+	ScorerParams           common.ScorerParams `json:"scorerParams"`
+	Language               string              `json:"language"`
+	DuplicateDistance      int                 `json:"duplicateDistance"`
+	ScopeRules             common.ScopeRules   `json:"scopeRules"`
+	Schedule               string              `json:"schedule"`
+	NextRunAt              *time.Time          `json:"nextRunAt"`
+	ConfigVersion          int64               `json:"configVersion"`
+	Summary                *dtoSummary         `json:"summary"`
+}
+
+type dtoSummary struct {
+	SourceID int64  `json:"sourceId"`
+	Text     string `json:"text"`
 }
 
 func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
@@ -77,6 +101,17 @@ func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
 		MinWeight:              task.MinWeight,
 		MaxSources:             task.MaxSources,
 		MaxNeighboursForSource: task.MaxNeighboursForSource,
+		HostRequestsPerSecond:  task.HostRequestsPerSecond,
+		HostBurst:              task.HostBurst,
+		HostMaxInFlight:        task.HostMaxInFlight,
+		Scorer:                 task.Scorer,
+		ScorerParams:           common.NewScorerParams(task.ScorerParams),
+		Language:               task.Language,
+		DuplicateDistance:      task.DuplicateDistance,
+		ScopeRules:             common.NewScopeRules(task.ScopeRules),
+		Schedule:               task.Schedule,
+		NextRunAt:              task.NextRunAt,
+		ConfigVersion:          task.ConfigVersion,
 	}
 
 	if task.Status != task_model.StatusCreated && task.Status != task_model.StatusInPocessing {
@@ -87,8 +122,22 @@ func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
 		}
 
 		res.SourcesViewed = &launch.SourcesViewed
+		res.SourcesNew = &launch.Stats.New
+		res.SourcesChanged = &launch.Stats.Changed
+		res.SourcesUnchanged = &launch.Stats.Unchanged
+		res.SourcesGone = &launch.Stats.Gone
+		res.Skipped = lo.MapKeys(launch.Skipped, func(_ int64, reason page.SkipReason) string {
+			return string(reason)
+		})
 		res.LaunchDuration = utils.Ptr(launch.FinishedAt.Sub(launch.StartedAt))
 		res.ErrorMsg = common.ErrorSlugToMsg(launch.Error)
+
+		if launch.Summary != nil {
+			res.Summary = &dtoSummary{
+				SourceID: launch.Summary.SourceID,
+				Text:     launch.Summary.Text,
+			}
+		}
 	}
 
 	common.OK(w, res)
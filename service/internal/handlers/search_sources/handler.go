@@ -0,0 +1,111 @@
+package search_sources
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/K1flar/crawlers/internal/handlers/common"
+	"github.com/K1flar/crawlers/internal/models/source"
+	"github.com/K1flar/crawlers/internal/services/highlighter"
+	"github.com/K1flar/crawlers/internal/storage"
+	"github.com/samber/lo"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+type Handler struct {
+	log            *slog.Logger
+	sourceContents storage.SourceContents
+}
+
+func New(
+	log *slog.Logger,
+	sourceContents storage.SourceContents,
+) *Handler {
+	return &Handler{log, sourceContents}
+}
+
+type dtoRequest struct {
+	Query  string `json:"query"`
+	TaskID *int64 `json:"taskId"`
+	Limit  int64  `json:"limit"`
+	Offset int64  `json:"offset"`
+}
+
+type dtoResponse struct {
+	Hits []dtoHit `json:"hits"`
+}
+
+type dtoHit struct {
+	SourceID     int64    `json:"sourceId"`
+	URL          string   `json:"url"`
+	Title        string   `json:"title"`
+	TaskID       int64    `json:"taskId"`
+	Query        string   `json:"query"`
+	LaunchID     int64    `json:"launchId"`
+	LaunchNumber int64    `json:"launchNumber"`
+	Rank         float64  `json:"rank"`
+	Snippets     []string `json:"snippets"`
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			h.log.Error(err.Error())
+		}
+	}()
+
+	dto, err := common.DTO[dtoRequest](r)
+	if err != nil {
+		common.BadRequest(w, "bad request body")
+		return
+	}
+
+	query := strings.TrimSpace(dto.Query)
+	if query == "" {
+		common.BadRequest(w, "empty query")
+		return
+	}
+
+	limit := dto.Limit
+	if limit <= 0 || limit > maxLimit {
+		limit = defaultLimit
+	}
+
+	hits, err := h.sourceContents.Search(ctx, storage.FilterSearchSources{
+		Query:  query,
+		TaskID: dto.TaskID,
+		Limit:  limit,
+		Offset: dto.Offset,
+	})
+	if err != nil {
+		common.Error(w, err)
+		return
+	}
+
+	hl := highlighter.New(query)
+
+	common.OK(w, dtoResponse{
+		Hits: lo.Map(hits, func(hit source.SearchHit, _ int) dtoHit {
+			return dtoHit{
+				SourceID:     hit.SourceID,
+				URL:          hit.URL,
+				Title:        hit.Title,
+				TaskID:       hit.TaskID,
+				Query:        hit.Query,
+				LaunchID:     hit.LaunchID,
+				LaunchNumber: hit.LaunchNumber,
+				Rank:         hit.Rank,
+				Snippets:     hl.Snippets(hit.Content),
+			}
+		}),
+	})
+}
@@ -1,11 +1,16 @@
 package update_task
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/K1flar/crawlers/internal/handlers/common"
+	"github.com/K1flar/crawlers/internal/models/task"
+	"github.com/K1flar/crawlers/internal/services/task_config"
 	"github.com/K1flar/crawlers/internal/storage"
+	"github.com/K1flar/crawlers/internal/utils"
 )
 
 type Handler struct {
@@ -20,12 +25,10 @@ func New(
 	return &Handler{log, tasks}
 }
 
+// dtoRequest - измененные параметры обхода задачи, незаданные параметры не меняются
 type dtoRequest struct {
-	ID                     int64    `json:"id"`
-	DepthLevel             *int     `json:"depthLevel"`
-	MinWeight              *float64 `json:"minWeight"`
-	MaxSources             *int64   `json:"maxSources"`
-	MaxNeighboursForSource *int64   `json:"maxNeighboursForSource"`
+	ID int64 `json:"id"`
+	common.ConfigPatch
 }
 
 func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
@@ -45,14 +48,30 @@ func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.tasks.Update(ctx, storage.ToUpdateTask{
-		ID:                     dto.ID,
-		DepthLevel:             dto.DepthLevel,
-		MinWeight:              dto.MinWeight,
-		MaxSources:             dto.MaxSources,
-		MaxNeighboursForSource: dto.MaxNeighboursForSource,
+	patch := dto.Patch()
+
+	err = h.tasks.Update(ctx, dto.ID, func(t task.Task) (storage.ToUpdateTask, error) {
+		cfg := patch.Apply(t.Config)
+
+		if err := task_config.Validate(cfg); err != nil {
+			return storage.ToUpdateTask{}, err
+		}
+
+		params := storage.ToUpdateTask{Config: cfg}
+
+		if cfg.Schedule != t.Schedule {
+			params.NextRunAt = utils.Ptr(task_config.NextRun(cfg.Schedule, time.Now()))
+		}
+
+		return params, nil
 	})
 	if err != nil {
+		var configError *task_config.Error
+		if errors.As(err, &configError) {
+			common.BadRequest(w, configError.Error())
+			return
+		}
+
 		common.Error(w, err)
 		return
 	}
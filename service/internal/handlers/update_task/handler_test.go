@@ -0,0 +1,80 @@
+package update_task
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/K1flar/crawlers/internal/models/task"
+	"github.com/K1flar/crawlers/internal/services/task_config"
+	"github.com/K1flar/crawlers/internal/storage"
+)
+
+// tasksStub отдает apply сохраненную задачу и запоминает результат
+type tasksStub struct {
+	storage.Tasks
+
+	task  task.Task
+	saved *storage.ToUpdateTask
+}
+
+func (s *tasksStub) Update(_ context.Context, id int64, apply func(t task.Task) (storage.ToUpdateTask, error)) error {
+	params, err := apply(s.task)
+	if err != nil {
+		return err
+	}
+
+	s.saved = &params
+
+	return nil
+}
+
+func TestHandle(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		wantStatus    int
+		wantDistance  int
+		wantNextRunAt bool
+	}{
+		{"patch keeps unset fields", `{"id": 1, "duplicateDistance": 0}`, http.StatusNoContent, 0, false},
+		{"schedule change moves next run", `{"id": 1, "schedule": "2h"}`, http.StatusNoContent, 3, true},
+		{"negative duplicate distance", `{"id": 1, "duplicateDistance": -1}`, http.StatusBadRequest, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := task_config.Default()
+			cfg.Schedule = "12h"
+
+			tasks := &tasksStub{task: task.Task{ID: 1, Config: cfg}}
+			h := New(slog.New(slog.NewTextHandler(io.Discard, nil)), tasks)
+
+			w := httptest.NewRecorder()
+			h.Handle(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body)))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+
+			if tt.wantStatus != http.StatusNoContent {
+				if tasks.saved != nil {
+					t.Fatalf("invalid config saved: %+v", tasks.saved.Config)
+				}
+				return
+			}
+
+			if tasks.saved.Config.DuplicateDistance != tt.wantDistance || tasks.saved.Config.DepthLevel != cfg.DepthLevel {
+				t.Fatalf("unexpected config %+v", tasks.saved.Config)
+			}
+
+			if (tasks.saved.NextRunAt != nil) != tt.wantNextRunAt {
+				t.Fatalf("next run at %v, want set %t", tasks.saved.NextRunAt, tt.wantNextRunAt)
+			}
+		})
+	}
+}
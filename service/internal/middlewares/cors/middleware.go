@@ -6,6 +6,8 @@ func New() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
+			// Имя файла выгрузки базы знаний
+			w.Header().Set("Access-Control-Expose-Headers", "Content-Disposition")
 
 			next.ServeHTTP(w, r)
 		})
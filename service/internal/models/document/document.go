@@ -4,4 +4,10 @@ type Document struct {
 	URL  string
 	Size int64
 	TF   map[string]int64
+
+	TitleSize int64
+	TitleTF   map[string]int64
+
+	// Позиции терминов запроса в тексте документа
+	Positions map[string][]int
 }
@@ -2,6 +2,8 @@ package launch
 
 import (
 	"time"
+
+	"github.com/K1flar/crawlers/internal/models/page"
 )
 
 type Status string
@@ -12,6 +14,8 @@ const (
 	StatusInProgress Status = "in_progress"
 	StatusFinished   Status = "finished"
 	StatusFailed     Status = "failed"
+	// Задачу остановили во время обхода, сохранены найденные к этому моменту источники
+	StatusCancelled Status = "cancelled"
 )
 
 type Launch struct {
@@ -23,4 +27,21 @@ type Launch struct {
 	SourcesViewed int64
 	Status        Status
 	Error         *ErrorSlug
+	Stats         Stats
+	Skipped       map[page.SkipReason]int64 // количество URL, которые краулер не стал загружать, по причинам
+	Summary       *Summary
+}
+
+// Summary - краткий пересказ самого релевантного источника запуска
+type Summary struct {
+	SourceID int64
+	Text     string
+}
+
+// Stats - изменения источников относительно прошлого запуска
+type Stats struct {
+	New       int64
+	Changed   int64
+	Unchanged int64
+	Gone      int64
 }
@@ -1,21 +1,80 @@
 package page
 
+import "time"
+
 type Status string
 
 const (
 	StatusAvailable   Status = "available"
 	StatusUnavailable Status = "unavailable"
+	// Сервер ответил 304, содержимое не изменилось с прошлого запуска
+	StatusNotModified Status = "not_modified"
 )
 
 type Page struct {
+	URL          string
+	Status       Status
+	Title        string
+	Content      string
+	Links        []Link
+	ETag         string
+	LastModified string
+	Headings     []Heading
+	Metadata     Metadata
+}
+
+// Metadata - сведения о странице из meta-тегов, OpenGraph/Twitter-карточек и JSON-LD
+type Metadata struct {
+	Description string
+	Language    string
+	Canonical   string
+	Author      string
+	SiteName    string
+	Image       string
+	PublishedAt *time.Time
+	ModifiedAt  *time.Time
+}
+
+// Link - ссылка со страницы
+type Link struct {
 	URL     string
-	Status  Status
-	Title   string
-	Content string
-	URLs    []string
+	Text    string   // текст ссылки, для картинок - alt
+	Rel     []string // значения атрибута rel: nofollow, sponsored, ugc и т.д.
+	Snippet string   // текст вокруг ссылки
+}
+
+type Heading struct {
+	Level int
+	Text  string
 }
 
+// Known - сведения о странице из прошлого запуска для условной загрузки
+type Known struct {
+	ETag         string
+	LastModified string
+	ContentHash  string
+	URLs         []string
+}
+
+// SkipReason - причина, по которой краулер не стал загружать URL
+type SkipReason string
+
+const (
+	SkipReasonDisallowedByRobots SkipReason = "disallowed_by_robots"
+	SkipReasonRobotsUnavailable  SkipReason = "robots_unavailable"
+	SkipReasonInvalidURL         SkipReason = "invalid_url"
+	SkipReasonOtherDomain        SkipReason = "other_domain"
+	SkipReasonHostNotAllowed     SkipReason = "host_not_allowed"
+	SkipReasonHostDenied         SkipReason = "host_denied"
+	SkipReasonPathNotAllowed     SkipReason = "path_not_allowed"
+	SkipReasonNotIncluded        SkipReason = "not_included"
+	SkipReasonExcluded           SkipReason = "excluded"
+	SkipReasonBlockedExtension   SkipReason = "blocked_extension"
+	SkipReasonFetchFailed        SkipReason = "fetch_failed"
+)
+
 type PageWithParentURL struct {
 	ParentURL *string
+	Link      *Link // ссылка на родительской странице, по которой найдена страница
 	*Page
 }
@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"github.com/K1flar/crawlers/internal/models/launch"
+	"github.com/K1flar/crawlers/internal/models/page"
+	"github.com/samber/lo"
 )
 
 type Status string
@@ -14,12 +16,55 @@ const (
 )
 
 type Source struct {
-	ID        int64
-	URL       string
-	Title     string
-	Status    Status
+	ID           int64
+	URL          string
+	Title        string
+	Status       Status
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	ETag         string
+	LastModified string
+	ContentHash  string
+	Metadata     page.Metadata
+}
+
+type Version struct {
+	ID          int64
+	SourceID    int64
+	Version     int64
+	LaunchID    *int64
+	Title       string
+	Status      Status
+	ContentHash string
+	CreatedAt   time.Time
+}
+
+type History struct {
+	Versions    []Version
+	From        *Version
+	To          *Version
+	TitleDiff   string
+	ContentDiff string
+}
+
+type Content struct {
+	SourceID  int64
+	LaunchID  int64
+	Text      string
+	Hash      string
 	CreatedAt time.Time
-	UpdatedAt time.Time
+}
+
+type SearchHit struct {
+	SourceID     int64
+	URL          string
+	Title        string
+	TaskID       int64
+	Query        string
+	LaunchID     int64
+	LaunchNumber int64
+	Rank         float64
+	Content      string
 }
 
 type ForTask struct {
@@ -27,7 +72,51 @@ type ForTask struct {
 	URL      string
 	Title    string
 	Weight   float64
+	Scorer   string
 	ParentID *int64
+	Metadata page.Metadata
+	Graph    GraphScores
+}
+
+// GraphScores - оценки источника по графу ссылок запуска
+type GraphScores struct {
+	PageRank  float64
+	Hub       float64
+	Authority float64
+}
+
+// Link - ссылка с одного источника на другой
+type Link struct {
+	FromSourceID int64
+	ToSourceID   *int64 // nil, если цель ссылки не сохранена в базе знаний
+	ToURL        string
+	Anchor       string
+	Rel          []string
+	Snippet      string
+}
+
+// Comparison - изменения базы знаний задачи между двумя запусками
+type Comparison struct {
+	From    launch.Launch
+	To      launch.Launch
+	Added   []ForTask
+	Removed []ForTask
+	Kept    []Kept
+}
+
+type Kept struct {
+	ForTask
+	PrevWeight   float64
+	PrevParentID *int64
+}
+
+func (k Kept) WeightDelta() float64 {
+	return k.Weight - k.PrevWeight
+}
+
+// Reparented - источник оказался в дереве под другим родителем
+func (k Kept) Reparented() bool {
+	return lo.FromPtr(k.ParentID) != lo.FromPtr(k.PrevParentID)
 }
 
 type ForProtocol struct {
@@ -45,4 +134,5 @@ type ForProtocol struct {
 	Duration        *time.Duration
 	LaunchStatus    launch.Status
 	LaunchErrorSlug *string
+	Metadata        page.Metadata
 }
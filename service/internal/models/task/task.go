@@ -14,16 +14,54 @@ const (
 )
 
 type Task struct {
-	ID                     int64
-	Query                  string
-	Status                 Status
-	CreatedAt              time.Time
-	UpdatedAt              time.Time
-	ProcessedAt            *time.Time
+	ID            int64
+	Query         string
+	Status        Status
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	ProcessedAt   *time.Time
+	NextRunAt     *time.Time
+	ConfigVersion int64
+	Config
+}
+
+// Config - параметры обхода задачи. Каждое изменение сохраняется новой версией
+type Config struct {
 	DepthLevel             int
 	MinWeight              float64
 	MaxSources             int64
 	MaxNeighboursForSource int64
+	HostRequestsPerSecond  float64
+	HostBurst              int64
+	HostMaxInFlight        int64
+	Scorer                 string
+	ScorerParams           ScorerParams
+	Language               string
+	DuplicateDistance      int // отрицательное значение отключает поиск дублей
+	ScopeRules             ScopeRules
+	Schedule               string // интервал или cron-выражение, пустое - раз в сутки
+}
+
+// ScorerParams - параметры функции ранжирования, незаданные берутся по умолчанию
+type ScorerParams struct {
+	K1          *float64
+	B           *float64
+	TitleWeight *float64
+	BodyWeight  *float64
+	// Вклад PageRank и авторитетности по HITS в вес источника, по умолчанию не учитываются
+	PageRankWeight  *float64
+	AuthorityWeight *float64
+}
+
+// ScopeRules - ограничения на URL, по которым переходит краулер. Пустые списки не ограничивают обход
+type ScopeRules struct {
+	SameDomain        bool     // переходить только по ссылкам на домен родительской страницы
+	AllowHosts        []string // хосты вместе с поддоменами
+	DenyHosts         []string
+	PathPrefixes      []string
+	Include           []string // регулярные выражения для всего URL
+	Exclude           []string
+	BlockedExtensions []string
 }
 
 type ForList struct {
@@ -0,0 +1,129 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/kljensen/snowball/english"
+	"github.com/kljensen/snowball/russian"
+)
+
+type Language string
+
+const (
+	Russian Language = "russian"
+	English Language = "english"
+	// Auto - язык определяется отдельно для каждой страницы
+	Auto Language = "auto"
+
+	Default = Auto
+)
+
+func ParseLanguage(s string) (Language, error) {
+	switch lang := Language(strings.ToLower(strings.TrimSpace(s))); lang {
+	case Russian, English, Auto:
+		return lang, nil
+	case "":
+		return Default, nil
+	}
+
+	return "", fmt.Errorf("unknown language [%s]", s)
+}
+
+type stemmer struct {
+	isStopWord func(word string) bool
+	stem       func(word string, stemStopWords bool) string
+}
+
+var stemmers = map[Language]stemmer{
+	Russian: {russian.IsStopWord, russian.Stem},
+	English: {english.IsStopWord, english.Stem},
+}
+
+// Analyzer приводит текст к списку терминов: разбивает на слова,
+// убирает стоп-слова и оставляет от слов основы
+type Analyzer struct {
+	lang Language
+}
+
+func New(lang Language) *Analyzer {
+	return &Analyzer{lang}
+}
+
+func (a *Analyzer) Analyze(text string) []string {
+	lang := a.lang
+	if lang == Auto {
+		lang = Detect(text)
+	}
+
+	tokens := Tokenize(text)
+	terms := make([]string, 0, len(tokens))
+
+	for _, token := range tokens {
+		tokenLang := lang
+
+		// На странице со смешанным текстом слова другой письменности обрабатываются своим языком
+		if a.lang == Auto {
+			if scriptLang, ok := script(token); ok {
+				tokenLang = scriptLang
+			}
+		}
+
+		stemmer, ok := stemmers[tokenLang]
+		if !ok {
+			terms = append(terms, token)
+			continue
+		}
+
+		if stemmer.isStopWord(token) {
+			continue
+		}
+
+		terms = append(terms, stemmer.stem(token, true))
+	}
+
+	return terms
+}
+
+// Tokenize разбивает текст на слова в нижнем регистре без знаков препинания
+func Tokenize(text string) []string {
+	text = strings.NewReplacer("ё", "е", "Ё", "Е").Replace(text)
+
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r)
+	})
+}
+
+// Detect определяет язык текста по преобладающей письменности
+func Detect(text string) Language {
+	var cyrillic, latin int
+
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+
+	if latin > cyrillic {
+		return English
+	}
+
+	return Russian
+}
+
+func script(token string) (Language, bool) {
+	for _, r := range token {
+		switch {
+		case unicode.Is(unicode.Cyrillic, r):
+			return Russian, true
+		case unicode.Is(unicode.Latin, r):
+			return English, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,126 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"punctuation", "Go, go! (GO)", []string{"go", "go", "go"}},
+		{"yo", "Ёлка, ёж", []string{"елка", "еж"}},
+		{"digits and mixed script", "HTTP/2 и веб-краулер", []string{"http", "2", "и", "веб", "краулер"}},
+		{"empty", " ,.- ", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want Language
+	}{
+		{"russian", "Поисковый робот обходит страницы", Russian},
+		{"english", "The crawler visits pages", English},
+		{"mostly english with russian words", "Crawler settings: глубина and weight", English},
+		{"mostly russian with english terms", "Настройки краулера: depth и вес страницы", Russian},
+		{"tie goes to russian", "abc где", Russian},
+		{"no letters", "2024 - 42", Russian},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Detect(tt.text); got != tt.want {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name string
+		lang Language
+		text string
+		want []string
+	}{
+		{"query word with punctuation matches", English, "Go, go!", []string{"go", "go"}},
+		{"english inflections stem together", English, "crawling crawled", []string{"crawl", "crawl"}},
+		{"russian inflections stem together", Russian, "краулер краулеры краулера краулером", []string{"краулер", "краулер", "краулер", "краулер"}},
+		{"russian adjectives stem together", Russian, "поисковый поисковые поисковых", []string{"поисков", "поисков", "поисков"}},
+		{"english stop words", English, "the crawler and the pages", []string{"crawler", "page"}},
+		{"russian stop words", Russian, "краулер и страницы на сайте", []string{"краулер", "страниц", "сайт"}},
+		{"other script of fixed language is kept as is", English, "crawler краулеры", []string{"crawler", "краулеры"}},
+		{"auto stems each script by its language", Auto, "краулеры для crawling", []string{"краулер", "crawl"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := New(tt.lang).Analyze(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// Один анализатор Auto обслуживает запрос и все страницы задачи, язык определяется для каждого текста
+func TestAutoAnalyzesEachPage(t *testing.T) {
+	a := New(Auto)
+
+	query := a.Analyze("краулеры crawlers")
+
+	pages := map[string]string{
+		"ru": "Сайты обходятся краулером",
+		"en": "Our crawler visits sites",
+	}
+
+	for name, text := range pages {
+		terms := a.Analyze(text)
+
+		found := false
+		for _, term := range terms {
+			for _, q := range query {
+				found = found || term == q
+			}
+		}
+
+		if !found {
+			t.Fatalf("page %s terms %q do not match query %q", name, terms, query)
+		}
+	}
+}
+
+func TestParseLanguage(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Language
+		wantErr bool
+	}{
+		{"", Default, false},
+		{" Russian ", Russian, false},
+		{"english", English, false},
+		{"auto", Auto, false},
+		{"german", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseLanguage(tt.in)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Fatalf("got %s, %v", got, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package content_extractor
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+
+	page_models "github.com/K1flar/crawlers/internal/models/page"
+	"github.com/PuerkitoBio/goquery"
+)
+
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// ExtractMetadata собирает сведения о странице из meta-тегов, OpenGraph/Twitter-карточек и JSON-LD.
+// Вызывается до Extract, которая удаляет скрипты вместе с JSON-LD
+func ExtractMetadata(doc *goquery.Document, base *url.URL) page_models.Metadata {
+	meta := metaTags(doc)
+	ld := jsonLD(doc)
+
+	metadata := page_models.Metadata{
+		Description: first(meta["description"], meta["og:description"], meta["twitter:description"]),
+		Language:    language(first(doc.Find("html").AttrOr("lang", ""), meta["content-language"], meta["og:locale"])),
+		Canonical:   resolve(base, doc.Find("link[rel~=canonical]").First().AttrOr("href", "")),
+		Author:      first(meta["author"], ld.author, meta["article:author"], meta["twitter:creator"]),
+		SiteName:    first(meta["og:site_name"], meta["application-name"]),
+		Image:       resolve(base, first(meta["og:image"], meta["og:image:url"], meta["twitter:image"])),
+		PublishedAt: parseDate(first(ld.datePublished, meta["article:published_time"], meta["date"])),
+		ModifiedAt:  parseDate(first(ld.dateModified, meta["article:modified_time"], meta["og:updated_time"])),
+	}
+
+	return metadata
+}
+
+// metaTags возвращает содержимое meta-тегов по name, property или http-equiv в нижнем регистре
+func metaTags(doc *goquery.Document) map[string]string {
+	tags := make(map[string]string)
+
+	doc.Find("meta[content]").Each(func(_ int, s *goquery.Selection) {
+		content := strings.TrimSpace(s.AttrOr("content", ""))
+		if content == "" {
+			return
+		}
+
+		for _, attr := range []string{"name", "property", "http-equiv"} {
+			key := strings.ToLower(strings.TrimSpace(s.AttrOr(attr, "")))
+			if key == "" {
+				continue
+			}
+
+			if _, ok := tags[key]; !ok {
+				tags[key] = content
+			}
+		}
+	})
+
+	return tags
+}
+
+type linkedData struct {
+	author        string
+	datePublished string
+	dateModified  string
+}
+
+func jsonLD(doc *goquery.Document) linkedData {
+	var ld linkedData
+
+	doc.Find(`script[type="application/ld+json"]`).Each(func(_ int, s *goquery.Selection) {
+		var v any
+		if err := json.Unmarshal([]byte(s.Text()), &v); err != nil {
+			return
+		}
+
+		ld.collect(v)
+	})
+
+	return ld
+}
+
+// collect обходит JSON-LD, включая @graph и вложенные объекты, и запоминает первые найденные значения
+func (ld *linkedData) collect(v any) {
+	switch v := v.(type) {
+	case []any:
+		for _, item := range v {
+			ld.collect(item)
+		}
+	case map[string]any:
+		if ld.datePublished == "" {
+			ld.datePublished, _ = v["datePublished"].(string)
+		}
+
+		if ld.dateModified == "" {
+			ld.dateModified, _ = v["dateModified"].(string)
+		}
+
+		if ld.author == "" {
+			ld.author = authorName(v["author"])
+		}
+
+		for _, key := range []string{"@graph", "mainEntity", "mainEntityOfPage"} {
+			if nested, ok := v[key]; ok {
+				ld.collect(nested)
+			}
+		}
+	}
+}
+
+func authorName(v any) string {
+	switch v := v.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case map[string]any:
+		name, _ := v["name"].(string)
+		return strings.TrimSpace(name)
+	case []any:
+		names := make([]string, 0, len(v))
+		for _, item := range v {
+			if name := authorName(item); name != "" {
+				names = append(names, name)
+			}
+		}
+
+		return strings.Join(names, ", ")
+	}
+
+	return ""
+}
+
+// language оставляет от кода языка только основной подтег: "ru-RU" -> "ru"
+func language(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+
+	if i := strings.IndexAny(code, "-_"); i > 0 {
+		code = code[:i]
+	}
+
+	return code
+}
+
+func resolve(base *url.URL, href string) string {
+	href = strings.TrimSpace(href)
+	if href == "" {
+		return ""
+	}
+
+	ref, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+
+	if base == nil {
+		return ref.String()
+	}
+
+	return base.ResolveReference(ref).String()
+}
+
+func parseDate(s string) *time.Time {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return &t
+		}
+	}
+
+	return nil
+}
+
+func first(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
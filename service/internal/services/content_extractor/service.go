@@ -0,0 +1,311 @@
+package content_extractor
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	page_models "github.com/K1flar/crawlers/internal/models/page"
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+const (
+	// Более короткие абзацы не участвуют в оценке блоков
+	minParagraphLen = 25
+	// Длинные абзацы рядом с основным блоком считаются его продолжением
+	minSiblingParagraphLen = 80
+
+	minSiblingScore   = 10
+	siblingScoreRatio = 0.2
+
+	// Если в основном блоке меньше этой доли текста страницы, блок выбран неудачно
+	minContentShare = 0.05
+)
+
+// Элементы, которые никогда не относятся к основному тексту страницы
+const boilerplateSelector = "script, style, noscript, template, iframe, svg, canvas, object, embed, " +
+	"button, input, select, textarea, nav, aside, dialog, " +
+	"[role=navigation], [role=banner], [role=contentinfo], [role=complementary], [role=dialog], " +
+	"[aria-hidden=true], [hidden]"
+
+const paragraphSelector = "p, pre, td, blockquote, dd"
+
+var (
+	unlikelyRegex = regexp.MustCompile(`(?i)banner|breadcrumb|combx|comment|community|cookie|consent|disqus|` +
+		`footer|header|menu|modal|nav|pager|pagination|popup|promo|related|share|sidebar|social|sponsor|subscribe|advert`)
+	likelyRegex = regexp.MustCompile(`(?i)article|body|content|entry|main|page|post|text|blog|story`)
+)
+
+var blockTags = map[string]struct{}{
+	"address": {}, "article": {}, "blockquote": {}, "br": {}, "dd": {}, "div": {}, "dl": {}, "dt": {},
+	"figcaption": {}, "figure": {}, "footer": {}, "h1": {}, "h2": {}, "h3": {}, "h4": {}, "h5": {}, "h6": {},
+	"header": {}, "hr": {}, "li": {}, "main": {}, "ol": {}, "p": {}, "pre": {}, "section": {}, "table": {},
+	"td": {}, "th": {}, "tr": {}, "ul": {},
+}
+
+type Content struct {
+	Text     string
+	Headings []page_models.Heading
+}
+
+// Extract выделяет основной текст страницы по плотности текста в блоках, отбрасывая
+// меню, подвалы, баннеры и прочее оформление. Документ изменяется
+func Extract(doc *goquery.Document) Content {
+	removeBoilerplate(doc)
+
+	body := doc.Find("body")
+	if body.Length() == 0 {
+		body = doc.Selection
+	}
+
+	content := Content{
+		Headings: extractHeadings(body),
+	}
+
+	all := render(body.Nodes)
+
+	if nodes := mainContent(body); len(nodes) > 0 {
+		text := render(nodes)
+		if float64(utf8.RuneCountInString(text)) >= minContentShare*float64(utf8.RuneCountInString(all)) {
+			content.Text = text
+			return content
+		}
+	}
+
+	content.Text = all
+
+	return content
+}
+
+func removeBoilerplate(doc *goquery.Document) {
+	doc.Find(boilerplateSelector).Remove()
+
+	// Шапка и подвал статьи часто содержат ее заголовок и дату, поэтому удаляются только шапка и подвал сайта
+	doc.Find("header, footer").Not("article header, article footer, main header, main footer").Remove()
+
+	doc.Find("*").Each(func(_ int, s *goquery.Selection) {
+		switch goquery.NodeName(s) {
+		case "html", "body", "article", "main":
+			return
+		}
+
+		attrs := s.AttrOr("class", "") + " " + s.AttrOr("id", "")
+		if unlikelyRegex.MatchString(attrs) && !likelyRegex.MatchString(attrs) {
+			s.Remove()
+		}
+	})
+}
+
+func extractHeadings(s *goquery.Selection) []page_models.Heading {
+	headings := make([]page_models.Heading, 0)
+
+	s.Find("h1, h2, h3, h4, h5, h6").Each(func(_ int, h *goquery.Selection) {
+		text := strings.Join(strings.Fields(h.Text()), " ")
+		if text == "" {
+			return
+		}
+
+		headings = append(headings, page_models.Heading{
+			Level: int(goquery.NodeName(h)[1] - '0'),
+			Text:  text,
+		})
+	})
+
+	return headings
+}
+
+// mainContent возвращает лучший по оценке блок вместе с похожими на него соседями.
+// Из блоков с одинаковой оценкой выбирается первый в документе
+func mainContent(body *goquery.Selection) []*html.Node {
+	scores := make(map[*html.Node]float64)
+	candidates := make([]*html.Node, 0)
+
+	addScore := func(s *goquery.Selection, score float64) {
+		if s.Length() == 0 {
+			return
+		}
+
+		node := s.Nodes[0]
+		if _, ok := scores[node]; !ok {
+			scores[node] = initialScore(s)
+			candidates = append(candidates, node)
+		}
+
+		scores[node] += score
+	}
+
+	body.Find(paragraphSelector).Each(func(_ int, p *goquery.Selection) {
+		text := strings.TrimSpace(p.Text())
+
+		length := utf8.RuneCountInString(text)
+		if length < minParagraphLen {
+			return
+		}
+
+		score := 1 + float64(strings.Count(text, ",")) + min(float64(length)/100, 3)
+
+		parent := p.Parent()
+		addScore(parent, score)
+		addScore(parent.Parent(), score/2)
+	})
+
+	// Порядок элементов в документе; body и его предки идут раньше любого элемента внутри body
+	position := make(map[*html.Node]int)
+	body.Find("*").Each(func(i int, s *goquery.Selection) {
+		position[s.Nodes[0]] = i
+	})
+
+	order := func(node *html.Node) int {
+		if i, ok := position[node]; ok {
+			return i
+		}
+
+		return -1
+	}
+
+	var (
+		top      *html.Node
+		topScore float64
+	)
+
+	for _, node := range candidates {
+		score := scores[node] * (1 - linkDensity(goquery.NewDocumentFromNode(node).Selection))
+		scores[node] = score
+
+		if top == nil || score > topScore || score == topScore && order(node) < order(top) {
+			top, topScore = node, score
+		}
+	}
+
+	if top == nil {
+		return nil
+	}
+
+	if top.Parent == nil {
+		return []*html.Node{top}
+	}
+
+	threshold := max(minSiblingScore, topScore*siblingScoreRatio)
+
+	nodes := make([]*html.Node, 0)
+
+	for sibling := top.Parent.FirstChild; sibling != nil; sibling = sibling.NextSibling {
+		if sibling.Type != html.ElementNode {
+			continue
+		}
+
+		if sibling == top || scores[sibling] >= threshold || isSiblingParagraph(sibling) {
+			nodes = append(nodes, sibling)
+		}
+	}
+
+	return nodes
+}
+
+func initialScore(s *goquery.Selection) float64 {
+	var score float64
+
+	switch goquery.NodeName(s) {
+	case "article", "main":
+		score += 10
+	case "div", "section":
+		score += 5
+	case "pre", "td", "blockquote":
+		score += 3
+	case "form", "ol", "ul", "dl", "dd", "dt", "li":
+		score -= 3
+	case "h1", "h2", "h3", "h4", "h5", "h6", "th":
+		score -= 5
+	}
+
+	attrs := s.AttrOr("class", "") + " " + s.AttrOr("id", "")
+
+	if likelyRegex.MatchString(attrs) {
+		score += 25
+	}
+
+	if unlikelyRegex.MatchString(attrs) {
+		score -= 25
+	}
+
+	return score
+}
+
+func isSiblingParagraph(node *html.Node) bool {
+	if node.Data != "p" {
+		return false
+	}
+
+	s := goquery.NewDocumentFromNode(node).Selection
+
+	return utf8.RuneCountInString(strings.TrimSpace(s.Text())) >= minSiblingParagraphLen && linkDensity(s) < 0.25
+}
+
+// linkDensity - доля текста блока, находящегося внутри ссылок
+func linkDensity(s *goquery.Selection) float64 {
+	total := utf8.RuneCountInString(strings.TrimSpace(s.Text()))
+	if total == 0 {
+		return 0
+	}
+
+	links := 0
+	s.Find("a").Each(func(_ int, a *goquery.Selection) {
+		links += utf8.RuneCountInString(strings.TrimSpace(a.Text()))
+	})
+
+	return min(float64(links)/float64(total), 1)
+}
+
+// render собирает текст узлов, разделяя блочные элементы переводами строк
+func render(nodes []*html.Node) string {
+	var sb strings.Builder
+
+	var walk func(node *html.Node, pre bool)
+	walk = func(node *html.Node, pre bool) {
+		switch node.Type {
+		case html.TextNode:
+			if pre {
+				sb.WriteString(node.Data)
+				return
+			}
+
+			if text := strings.Join(strings.Fields(node.Data), " "); text != "" {
+				sb.WriteString(text)
+				sb.WriteByte(' ')
+			}
+
+			return
+		case html.ElementNode, html.DocumentNode:
+		default:
+			return
+		}
+
+		_, block := blockTags[node.Data]
+		if block {
+			sb.WriteByte('\n')
+		}
+
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child, pre || node.Data == "pre")
+		}
+
+		if block {
+			sb.WriteByte('\n')
+		}
+	}
+
+	for _, node := range nodes {
+		walk(node, false)
+	}
+
+	lines := make([]string, 0)
+
+	for _, line := range strings.Split(sb.String(), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,88 @@
+package content_extractor
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	page_models "github.com/K1flar/crawlers/internal/models/page"
+	"github.com/PuerkitoBio/goquery"
+)
+
+var update = flag.Bool("update", false, "перезаписать ожидаемые результаты в testdata")
+
+type golden struct {
+	Text     string
+	Headings []page_models.Heading
+	Metadata page_models.Metadata
+}
+
+// TestGolden сравнивает результат разбора testdata/*.html с testdata/*.golden.json
+func TestGolden(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base, _ := url.Parse("https://example.com/articles/page")
+
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".html")
+
+		t.Run(name, func(t *testing.T) {
+			raw, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Разбор повторяется, чтобы поймать зависимость от порядка обхода map
+			var first []byte
+			for range 10 {
+				doc, err := goquery.NewDocumentFromReader(bytes.NewReader(raw))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				metadata := ExtractMetadata(doc, base)
+				content := Extract(doc)
+
+				got, err := json.MarshalIndent(golden{
+					Text:     content.Text,
+					Headings: content.Headings,
+					Metadata: metadata,
+				}, "", "  ")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if first == nil {
+					first = got
+				} else if !bytes.Equal(first, got) {
+					t.Fatalf("result is not deterministic:\n%s\n%s", first, got)
+				}
+			}
+
+			goldenFile := filepath.Join("testdata", name+".golden.json")
+
+			if *update {
+				if err := os.WriteFile(goldenFile, append(first, '\n'), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(goldenFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(bytes.TrimSpace(want), bytes.TrimSpace(first)) {
+				t.Fatalf("got:\n%s\nwant:\n%s", first, want)
+			}
+		})
+	}
+}
@@ -4,14 +4,18 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"sync"
 	"time"
 
 	"github.com/K1flar/crawlers/internal/business_errors"
 	"github.com/K1flar/crawlers/internal/gates"
 	"github.com/K1flar/crawlers/internal/models/page"
 	"github.com/K1flar/crawlers/internal/models/task"
+	"github.com/K1flar/crawlers/internal/services/analyzer"
+	"github.com/K1flar/crawlers/internal/services/politeness"
+	"github.com/K1flar/crawlers/internal/services/scope"
+	"github.com/K1flar/crawlers/internal/services/url_normalizer"
 	"github.com/gammazero/workerpool"
+	"github.com/samber/lo"
 )
 
 const (
@@ -22,6 +26,7 @@ type Crawler struct {
 	log          *slog.Logger
 	searchSystem gates.SearchSystem
 	webScraper   gates.WebScraper
+	robots       gates.RobotsTxt
 	now          func() time.Time
 }
 
@@ -29,25 +34,34 @@ func New(
 	log *slog.Logger,
 	searchSystem gates.SearchSystem,
 	webScraper gates.WebScraper,
+	robots gates.RobotsTxt,
 ) *Crawler {
 	return &Crawler{
 		log:          log,
 		searchSystem: searchSystem,
 		webScraper:   webScraper,
+		robots:       robots,
 		now:          time.Now,
 	}
 }
 
-func (c *Crawler) Start(ctx context.Context, task task.Task) (map[string]*page.PageWithParentURL, error) {
-	instance := c.newInstance(task)
+func (c *Crawler) Start(
+	ctx context.Context,
+	task task.Task,
+	known map[string]page.Known,
+) (map[string]*page.PageWithParentURL, map[page.SkipReason]int64, error) {
+	instance, err := c.newInstance(task, known)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create crawler instance: %w", err)
+	}
 
 	urls, err := c.searchSystem.Search(ctx, task.Query)
 	if err != nil {
-		return nil, fmt.Errorf("%w: failed to search initial sources by query [%s]: %w", business_errors.SearxError, task.Query, err)
+		return nil, nil, fmt.Errorf("%w: failed to search initial sources by query [%s]: %w", business_errors.SearxError, task.Query, err)
 	}
 
 	if len(urls) == 0 {
-		return nil, business_errors.ZeroStartSources
+		return nil, nil, business_errors.ZeroStartSources
 	}
 
 	timeStart := c.now()
@@ -55,24 +69,69 @@ func (c *Crawler) Start(ctx context.Context, task task.Task) (map[string]*page.P
 
 	err = instance.start(ctx, urls)
 	if err != nil {
-		return nil, fmt.Errorf("failed to run crawler instance: %w", err)
+		return nil, nil, fmt.Errorf("failed to run crawler instance: %w", err)
 	}
 
-	c.log.Info(fmt.Sprintf("end search for task [%d] with %d sources. %s", task.ID, len(instance.pages), time.Since(timeStart)))
+	skipped := instance.skipStats()
+
+	c.log.Info(fmt.Sprintf("end search for task [%d] with %d sources, %d urls skipped. %s", task.ID, len(instance.pages), lo.Sum(lo.Values(skipped)), time.Since(timeStart)))
+
+	// При отмене возвращаются страницы, загруженные до нее
+	if ctx.Err() != nil {
+		return instance.pages, skipped, ctx.Err()
+	}
 
-	return instance.pages, nil
+	return instance.pages, skipped, nil
 }
 
-func (c *Crawler) newInstance(task task.Task) *crawlerInstance {
+func (c *Crawler) newInstance(task task.Task, known map[string]page.Known) (*crawlerInstance, error) {
+	urlScope, err := scope.New(task.ScopeRules)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ограничения на хост задаются для каждой задачи отдельно
+	webScraper := politeness.NewScraper(c.webScraper, c.robots, politeness.Config{
+		RequestsPerSecond: task.HostRequestsPerSecond,
+		Burst:             int(task.HostBurst),
+		MaxInFlight:       int(task.HostMaxInFlight),
+	})
+
+	lang, err := analyzer.ParseLanguage(task.Language)
+	if err != nil {
+		return nil, err
+	}
+
 	return &crawlerInstance{
-		task:         task,
-		webScraper:   c.webScraper,
-		wp:           workerpool.New(countWorkers),
-		stop:         make(chan struct{}),
-		pending:      0,
-		pendingLock:  &sync.Mutex{},
-		crawlerTasks: make(chan crawlerTask),
-		visited:      make(map[string]struct{}, task.MaxSources),
-		pages:        make(map[string]*page.PageWithParentURL, task.MaxSources),
+		log:         c.log,
+		task:        task,
+		webScraper:  webScraper,
+		robots:      c.robots,
+		scope:       urlScope,
+		prioritizer: newPrioritizer(task.Query, analyzer.New(lang)),
+		wp:          workerpool.New(countWorkers),
+		results:     make(chan crawlerResult),
+		frontier:    newFrontier(),
+		queued:      make(map[string]struct{}),
+		visited:     make(map[string]struct{}, task.MaxSources),
+		skipped:     make(map[string]page.SkipReason),
+		rejected:    make(map[string]page.SkipReason),
+		known:       normalizeKnown(known),
+		pages:       make(map[string]*page.PageWithParentURL, task.MaxSources),
+	}, nil
+}
+
+// normalizeKnown приводит к каноническому виду URL, сохраненные до появления нормализации
+func normalizeKnown(known map[string]page.Known) map[string]page.Known {
+	res := make(map[string]page.Known, len(known))
+
+	for url, k := range known {
+		if normalized, err := url_normalizer.Normalize(url); err == nil {
+			url = normalized
+		}
+
+		res[url] = k
 	}
+
+	return res
 }
@@ -3,30 +3,40 @@ package crawler
 import (
 	"context"
 	"fmt"
-	"sync"
+	"log/slog"
+	"sort"
 
 	"github.com/K1flar/crawlers/internal/gates"
 	page_models "github.com/K1flar/crawlers/internal/models/page"
 	"github.com/K1flar/crawlers/internal/models/task"
+	"github.com/K1flar/crawlers/internal/services/scope"
+	"github.com/K1flar/crawlers/internal/services/url_normalizer"
 	"github.com/gammazero/workerpool"
 	"github.com/samber/lo"
 )
 
-type crawlerTask struct {
-	DepthLevel int
-	Page       *page_models.PageWithParentURL
+type crawlerResult struct {
+	Candidate candidate
+	Page      *page_models.Page
 }
 
 type crawlerInstance struct {
-	task         task.Task
-	webScraper   gates.WebScraper
-	wp           *workerpool.WorkerPool
-	crawlerTasks chan crawlerTask
-	stop         chan struct{}
-	pending      int64
-	pendingLock  *sync.Mutex
-	visited      map[string]struct{}
-	pages        map[string]*page_models.PageWithParentURL
+	log         *slog.Logger
+	task        task.Task
+	webScraper  gates.WebScraper
+	robots      gates.RobotsTxt
+	scope       *scope.Scope
+	prioritizer *prioritizer
+	wp          *workerpool.WorkerPool
+	results     chan crawlerResult
+	frontier    *frontier
+	inFlight    int
+	queued      map[string]struct{} // ключи url_normalizer.Key URL в очереди и в загрузке
+	visited     map[string]struct{} // ключи url_normalizer.Key загруженных страниц
+	skipped     map[string]page_models.SkipReason
+	rejected    map[string]page_models.SkipReason // отказы по правилам обхода, зависящие от родительской страницы
+	known       map[string]page_models.Known
+	pages       map[string]*page_models.PageWithParentURL
 }
 
 func (c *crawlerInstance) start(ctx context.Context, urls []string) error {
@@ -34,109 +44,279 @@ func (c *crawlerInstance) start(ctx context.Context, urls []string) error {
 		return nil
 	}
 
-	fmt.Println("start urls: ", len(urls))
+	c.log.Debug(fmt.Sprintf("start crawling %d urls for task [%d]", len(urls), c.task.ID))
 
-	go c.crawl(ctx)
+	links := lo.Map(urls, func(url string, _ int) page_models.Link {
+		return page_models.Link{URL: url}
+	})
 
-	for _, url := range urls {
-		url := url
-		c.incPending()
-		c.wp.Submit(func() {
-			page, _ := c.webScraper.GetPage(ctx, url)
+	seeds := lo.Map(c.filterLinks("", links), func(link page_models.Link, _ int) candidate {
+		return candidate{
+			URL:        link.URL,
+			DepthLevel: 1,
+			Priority:   seedPriority,
+		}
+	})
 
-			c.crawlerTasks <- crawlerTask{
-				DepthLevel: 1,
-				Page:       &page_models.PageWithParentURL{Page: page},
-			}
-		})
+	c.enqueue(ctx, seeds, len(seeds))
+	c.dispatch(ctx)
+
+	for c.inFlight > 0 {
+		res := <-c.results
+		c.inFlight--
+
+		c.handle(ctx, res)
+		c.dispatch(ctx)
 	}
 
-	<-c.stop
 	c.wp.Stop()
-	close(c.crawlerTasks)
 
 	return nil
 }
 
-func (c *crawlerInstance) crawl(ctx context.Context) {
-	for task := range c.crawlerTasks {
-		c.decPending()
+// dispatch отдает на загрузку самые приоритетные URL, пока есть свободные воркеры и не исчерпан лимит страниц
+func (c *crawlerInstance) dispatch(ctx context.Context) {
+	for c.inFlight < countWorkers && c.frontier.len() > 0 && len(c.pages)+c.inFlight < int(c.task.MaxSources) {
+		if ctx.Err() != nil {
+			return
+		}
+
+		next := c.frontier.pop()
 
-		if task.Page == nil || task.Page.Page == nil {
+		c.inFlight++
+		c.wp.Submit(func() {
+			c.results <- crawlerResult{
+				Candidate: next,
+				Page:      c.getPage(ctx, next.URL),
+			}
+		})
+	}
+}
+
+func (c *crawlerInstance) handle(ctx context.Context, res crawlerResult) {
+	if res.Page == nil {
+		if ctx.Err() == nil {
+			c.skip(res.Candidate.URL, page_models.SkipReasonFetchFailed)
+		}
+		return
+	}
+
+	// Страница могла быть загружена раньше по другому адресу
+	requestedKey, _ := url_normalizer.Key(res.Candidate.URL)
+	pageKey, _ := url_normalizer.Key(res.Page.URL)
+
+	_, requestedVisited := c.visited[requestedKey]
+	_, pageVisited := c.visited[pageKey]
+
+	c.visited[requestedKey] = struct{}{}
+	c.visited[pageKey] = struct{}{}
+
+	if requestedVisited || pageVisited {
+		return
+	}
+
+	c.pages[res.Page.URL] = &page_models.PageWithParentURL{
+		ParentURL: res.Candidate.ParentURL,
+		Link:      res.Candidate.Link,
+		Page:      res.Page,
+	}
+
+	if res.Candidate.DepthLevel >= c.task.DepthLevel {
+		return
+	}
+
+	c.expand(ctx, res.Candidate.DepthLevel, res.Page)
+}
+
+// expand добавляет в очередь не более MaxNeighboursForSource самых перспективных ссылок страницы
+func (c *crawlerInstance) expand(ctx context.Context, depthLevel int, page *page_models.Page) {
+	relevance := c.prioritizer.pageRelevance(page)
+	parentURL := page.URL
+
+	candidates := lo.Map(c.filterLinks(page.URL, page.Links), func(link page_models.Link, _ int) candidate {
+		return candidate{
+			URL:        link.URL,
+			ParentURL:  &parentURL,
+			Link:       &link,
+			DepthLevel: depthLevel + 1,
+			Priority:   c.prioritizer.linkPriority(link, relevance),
+		}
+	})
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Priority > candidates[j].Priority
+	})
+
+	c.enqueue(ctx, candidates, int(c.task.MaxNeighboursForSource))
+}
+
+// enqueue добавляет в очередь не более limit URL, разрешенных robots.txt
+func (c *crawlerInstance) enqueue(ctx context.Context, candidates []candidate, limit int) {
+	added := 0
+
+	for _, cand := range candidates {
+		if added >= limit {
+			break
+		}
+
+		if !c.allowed(ctx, cand.URL) {
 			continue
 		}
 
-		if _, visited := c.visited[task.Page.URL]; visited {
+		key, _ := url_normalizer.Key(cand.URL)
+		c.queued[key] = struct{}{}
+
+		c.frontier.push(cand)
+		added++
+	}
+}
+
+func (c *crawlerInstance) getPage(ctx context.Context, url string) *page_models.Page {
+	var known *page_models.Known
+	if k, ok := c.known[url]; ok {
+		known = &k
+	}
+
+	page, _ := c.webScraper.GetPage(ctx, url, known)
+
+	if page == nil {
+		return nil
+	}
+
+	if page.Status == page_models.StatusNotModified && known != nil {
+		// Тело ответа 304 пустое, продолжаем обход по ссылкам прошлого запуска
+		page.Links = lo.Map(known.URLs, func(url string, _ int) page_models.Link {
+			return page_models.Link{URL: url}
+		})
+	}
+
+	page.URL = c.pageURL(ctx, url, page)
+
+	return page
+}
+
+// pageURL возвращает нормализованный URL страницы с учетом редиректов и rel=canonical.
+// Canonical принимается, только если переход на него разрешен правилами обхода и robots.txt
+func (c *crawlerInstance) pageURL(ctx context.Context, requested string, page *page_models.Page) string {
+	loaded := requested
+	if normalized, err := url_normalizer.Normalize(page.URL); err == nil {
+		loaded = normalized
+	}
+
+	if page.Metadata.Canonical == "" {
+		return loaded
+	}
+
+	canonical, err := url_normalizer.Normalize(page.Metadata.Canonical)
+	if err != nil {
+		return loaded
+	}
+
+	if reason, ok := c.scope.Check(loaded, canonical); !ok {
+		c.log.Debug(fmt.Sprintf("ignore canonical [%s] of [%s] for task [%d]: %s", canonical, loaded, c.task.ID, reason))
+		return loaded
+	}
+
+	// Вызывается из воркеров, поэтому отказ не запоминается в skipped
+	if allowed, err := c.robots.Allowed(ctx, canonical); err != nil || !allowed {
+		c.log.Debug(fmt.Sprintf("ignore canonical [%s] of [%s] for task [%d]: %s", canonical, loaded, c.task.ID, page_models.SkipReasonDisallowedByRobots))
+		return loaded
+	}
+
+	return canonical
+}
+
+// filterLinks нормализует URL ссылок, найденных на странице parent, и оставляет новые ссылки в рамках правил обхода
+func (c *crawlerInstance) filterLinks(parent string, links []page_models.Link) []page_models.Link {
+	res := make([]page_models.Link, 0, len(links))
+	keys := make(map[string]struct{}, len(links))
+
+	for _, link := range links {
+		url, err := url_normalizer.Normalize(link.URL)
+		if err != nil {
+			c.skip(link.URL, page_models.SkipReasonInvalidURL)
 			continue
 		}
 
-		c.visited[task.Page.URL] = struct{}{}
-		c.pages[task.Page.URL] = task.Page
+		key, _ := url_normalizer.Key(url)
 
-		if task.DepthLevel >= c.task.DepthLevel {
+		if _, visited := c.visited[key]; visited {
 			continue
 		}
 
-		if len(c.pages) > int(c.task.MaxSources) {
-			select {
-			case c.stop <- struct{}{}:
-			default:
-			}
+		if _, queued := c.queued[key]; queued {
 			continue
 		}
 
-		urls := c.filterURLs(task.Page.URLs)
+		if _, ok := keys[key]; ok {
+			continue
+		}
 
-		if len(urls) == 0 {
+		if _, skipped := c.skipped[url]; skipped {
 			continue
 		}
 
-		for _, url := range urls {
-			url := url
-			c.incPending()
-			c.wp.Submit(func() {
-				page, _ := c.webScraper.GetPage(ctx, url)
-
-				c.crawlerTasks <- crawlerTask{
-					DepthLevel: task.DepthLevel + 1,
-					Page: &page_models.PageWithParentURL{
-						ParentURL: &task.Page.URL,
-						Page:      page,
-					},
-				}
-			})
+		// Правила обхода зависят от родительской страницы, поэтому отказ не запоминается
+		if reason, ok := c.scope.Check(parent, url); !ok {
+			c.rejected[url] = reason
+			c.log.Debug(fmt.Sprintf("skip url [%s] for task [%d]: %s", url, c.task.ID, reason))
+			continue
 		}
 
+		keys[key] = struct{}{}
+
+		link.URL = url
+		res = append(res, link)
 	}
+
+	return res
 }
 
-func (c *crawlerInstance) filterURLs(urls []string) []string {
-	notVisited := lo.Filter(urls, func(url string, _ int) bool {
-		_, visited := c.visited[url]
-		return !visited
-	})
+// allowed проверяет URL по robots.txt и запоминает причину отказа
+func (c *crawlerInstance) allowed(ctx context.Context, url string) bool {
+	allowed, err := c.robots.Allowed(ctx, url)
+	if err != nil {
+		c.log.Debug(fmt.Sprintf("failed to check robots.txt for url [%s]: %s", url, err))
+		c.skip(url, page_models.SkipReasonRobotsUnavailable)
+		return false
+	}
 
-	if len(notVisited) > int(c.task.MaxNeighboursForSource) {
-		notVisited = notVisited[:c.task.MaxNeighboursForSource]
+	if !allowed {
+		c.skip(url, page_models.SkipReasonDisallowedByRobots)
+		return false
 	}
 
-	return notVisited
+	return true
 }
 
-func (c *crawlerInstance) incPending() {
-	c.pendingLock.Lock()
-	c.pending++
-	c.pendingLock.Unlock()
-}
+// skipStats считает пропущенные URL по причинам. Отказ по правилам обхода не учитывается,
+// если URL все же попал в обход по ссылке с другой страницы
+func (c *crawlerInstance) skipStats() map[page_models.SkipReason]int64 {
+	res := make(map[page_models.SkipReason]int64)
+
+	for _, reason := range c.skipped {
+		res[reason]++
+	}
 
-func (c *crawlerInstance) decPending() {
-	c.pendingLock.Lock()
-	c.pending--
-	if c.pending == 0 {
-		select {
-		case c.stop <- struct{}{}:
-		default:
+	for url, reason := range c.rejected {
+		if _, skipped := c.skipped[url]; skipped {
+			continue
+		}
+
+		key, _ := url_normalizer.Key(url)
+
+		_, visited := c.visited[key]
+		_, queued := c.queued[key]
+
+		if !visited && !queued {
+			res[reason]++
 		}
 	}
-	c.pendingLock.Unlock()
+
+	return res
+}
+
+func (c *crawlerInstance) skip(url string, reason page_models.SkipReason) {
+	c.skipped[url] = reason
+	c.log.Debug(fmt.Sprintf("skip url [%s] for task [%d]: %s", url, c.task.ID, reason))
 }
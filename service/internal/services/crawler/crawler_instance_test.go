@@ -0,0 +1,136 @@
+package crawler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	page_models "github.com/K1flar/crawlers/internal/models/page"
+	"github.com/K1flar/crawlers/internal/models/task"
+	"github.com/K1flar/crawlers/internal/services/scope"
+)
+
+type robotsStub struct {
+	disallowed []string
+}
+
+func (r robotsStub) Allowed(_ context.Context, url string) (bool, error) {
+	for _, prefix := range r.disallowed {
+		if strings.HasPrefix(url, prefix) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (r robotsStub) CrawlDelay(context.Context, string) (time.Duration, error) {
+	return 0, nil
+}
+
+func TestPageURLGatesCanonical(t *testing.T) {
+	tests := []struct {
+		name      string
+		rules     task.ScopeRules
+		robots    robotsStub
+		loaded    string
+		canonical string
+		want      string
+	}{
+		{
+			name:      "canonical on the same host",
+			loaded:    "https://example.com/a?ref=1",
+			canonical: "https://example.com/a",
+			want:      "https://example.com/a",
+		},
+		{
+			name:   "no canonical keeps redirect target",
+			loaded: "https://Example.com/b/",
+			want:   "https://example.com/b",
+		},
+		{
+			name:      "canonical on another domain",
+			rules:     task.ScopeRules{SameDomain: true},
+			loaded:    "https://example.com/a",
+			canonical: "https://other.org/a",
+			want:      "https://example.com/a",
+		},
+		{
+			name:      "canonical on a denied host",
+			rules:     task.ScopeRules{DenyHosts: []string{"spam.com"}},
+			loaded:    "https://example.com/a",
+			canonical: "https://spam.com/a",
+			want:      "https://example.com/a",
+		},
+		{
+			name:      "canonical disallowed by robots",
+			robots:    robotsStub{disallowed: []string{"https://example.com/private"}},
+			loaded:    "https://example.com/a",
+			canonical: "https://example.com/private/a",
+			want:      "https://example.com/a",
+		},
+		{
+			name:      "invalid canonical",
+			loaded:    "https://example.com/a",
+			canonical: "://",
+			want:      "https://example.com/a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := scope.New(tt.rules)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c := &crawlerInstance{
+				log:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+				robots: tt.robots,
+				scope:  s,
+			}
+
+			page := &page_models.Page{URL: tt.loaded}
+			page.Metadata.Canonical = tt.canonical
+
+			if got := c.pageURL(context.Background(), "https://example.com/requested", page); got != tt.want {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSkipStatsCountsURLsNeverCrawled(t *testing.T) {
+	c := &crawlerInstance{
+		skipped: map[string]page_models.SkipReason{
+			"https://example.com/private":   page_models.SkipReasonDisallowedByRobots,
+			"https://example.com/broken":    page_models.SkipReasonFetchFailed,
+			"https://example.com/forbidden": page_models.SkipReasonDisallowedByRobots,
+		},
+		rejected: map[string]page_models.SkipReason{
+			"https://other.org/a":         page_models.SkipReasonOtherDomain,
+			"https://other.org/visited":   page_models.SkipReasonOtherDomain,
+			"https://other.org/queued":    page_models.SkipReasonOtherDomain,
+			"https://example.com/a.png":   page_models.SkipReasonBlockedExtension,
+			"https://example.com/private": page_models.SkipReasonOtherDomain,
+		},
+		visited: map[string]struct{}{"other.org/visited": {}},
+		queued:  map[string]struct{}{"other.org/queued": {}},
+	}
+
+	got := c.skipStats()
+	want := map[page_models.SkipReason]int64{
+		page_models.SkipReasonDisallowedByRobots: 2,
+		page_models.SkipReasonFetchFailed:        1,
+		page_models.SkipReasonOtherDomain:        1,
+		page_models.SkipReasonBlockedExtension:   1,
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
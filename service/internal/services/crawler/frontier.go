@@ -0,0 +1,67 @@
+package crawler
+
+import (
+	"container/heap"
+
+	"github.com/K1flar/crawlers/internal/models/page"
+)
+
+// candidate - URL, ожидающий загрузки
+type candidate struct {
+	URL        string
+	ParentURL  *string
+	Link       *page.Link // ссылка на родительской странице
+	DepthLevel int
+	Priority   float64
+	seq        int64 // порядок добавления, при равном приоритете раньше загружается добавленный раньше
+}
+
+// frontier - очередь URL с приоритетом: первыми загружаются самые перспективные ссылки
+type frontier struct {
+	items candidates
+	seq   int64
+}
+
+func newFrontier() *frontier {
+	return &frontier{}
+}
+
+func (f *frontier) push(c candidate) {
+	c.seq = f.seq
+	f.seq++
+
+	heap.Push(&f.items, c)
+}
+
+func (f *frontier) pop() candidate {
+	return heap.Pop(&f.items).(candidate)
+}
+
+func (f *frontier) len() int {
+	return len(f.items)
+}
+
+type candidates []candidate
+
+func (c candidates) Len() int { return len(c) }
+
+func (c candidates) Less(i, j int) bool {
+	if c[i].Priority != c[j].Priority {
+		return c[i].Priority > c[j].Priority
+	}
+
+	return c[i].seq < c[j].seq
+}
+
+func (c candidates) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
+
+func (c *candidates) Push(x any) { *c = append(*c, x.(candidate)) }
+
+func (c *candidates) Pop() any {
+	old := *c
+	n := len(old)
+	item := old[n-1]
+	*c = old[:n-1]
+
+	return item
+}
@@ -0,0 +1,108 @@
+package crawler
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/K1flar/crawlers/internal/models/page"
+	"github.com/K1flar/crawlers/internal/services/analyzer"
+	"github.com/samber/lo"
+)
+
+const (
+	anchorWeight = 0.5
+	urlWeight    = 0.2
+	parentWeight = 0.3
+
+	// Для оценки страницы хватает ее начала
+	maxRelevanceContent = 2000
+
+	// Стартовые URL выбраны поисковой системой и загружаются первыми
+	seedPriority = 1.0
+
+	// Автор страницы не ручается за ссылки nofollow, sponsored и ugc
+	untrustedLinkFactor = 0.5
+)
+
+var untrustedRels = []string{"nofollow", "sponsored", "ugc"}
+
+// prioritizer оценивает, насколько ссылка может вести к странице по запросу задачи
+type prioritizer struct {
+	analyzer *analyzer.Analyzer
+	terms    map[string]struct{}
+}
+
+func newPrioritizer(query string, analyzer *analyzer.Analyzer) *prioritizer {
+	terms := make(map[string]struct{})
+	for _, term := range analyzer.Analyze(query) {
+		terms[term] = struct{}{}
+	}
+
+	return &prioritizer{analyzer, terms}
+}
+
+// pageRelevance - доля терминов запроса в заголовках и начале текста страницы
+func (p *prioritizer) pageRelevance(pg *page.Page) float64 {
+	parts := make([]string, 0, len(pg.Headings)+2)
+	parts = append(parts, pg.Title)
+
+	for _, h := range pg.Headings {
+		parts = append(parts, h.Text)
+	}
+
+	content := []rune(pg.Content)
+	if len(content) > maxRelevanceContent {
+		content = content[:maxRelevanceContent]
+	}
+	parts = append(parts, string(content))
+
+	return p.relevance(strings.Join(parts, " "))
+}
+
+// linkPriority учитывает текст ссылки, слова в адресе и релевантность страницы, на которой она найдена
+func (p *prioritizer) linkPriority(link page.Link, parentRelevance float64) float64 {
+	priority := anchorWeight*p.relevance(link.Text) +
+		urlWeight*p.relevance(urlText(link.URL)) +
+		parentWeight*parentRelevance
+
+	if lo.Some(link.Rel, untrustedRels) {
+		priority *= untrustedLinkFactor
+	}
+
+	return priority
+}
+
+func (p *prioritizer) relevance(text string) float64 {
+	if len(p.terms) == 0 {
+		return 0
+	}
+
+	found := make(map[string]struct{}, len(p.terms))
+	for _, token := range p.analyzer.Analyze(text) {
+		if _, ok := p.terms[token]; ok {
+			found[token] = struct{}{}
+		}
+	}
+
+	return float64(len(found)) / float64(len(p.terms))
+}
+
+// urlText возвращает слова из пути и параметров URL; хост не учитывается
+func urlText(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+
+	path, err := url.PathUnescape(u.Path)
+	if err != nil {
+		path = u.Path
+	}
+
+	query, err := url.QueryUnescape(u.RawQuery)
+	if err != nil {
+		query = u.RawQuery
+	}
+
+	return path + " " + query
+}
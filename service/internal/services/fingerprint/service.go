@@ -0,0 +1,51 @@
+package fingerprint
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+)
+
+const (
+	// Количество слов в шингле
+	shingleSize = 3
+
+	// У более коротких текстов отпечатки совпадают слишком часто
+	MinTokens = 20
+)
+
+// SimHash возвращает 64-битный отпечаток текста: у похожих текстов отпечатки отличаются в небольшом числе бит
+func SimHash(tokens []string) uint64 {
+	var counts [64]int
+
+	// Текст короче шингла считается одним шинглом
+	shingles := max(len(tokens)-shingleSize+1, min(len(tokens), 1))
+
+	for i := range shingles {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(tokens[i:min(i+shingleSize, len(tokens))], " ")))
+
+		sum := h.Sum64()
+		for bit := range counts {
+			if sum&(1<<bit) != 0 {
+				counts[bit]++
+			} else {
+				counts[bit]--
+			}
+		}
+	}
+
+	var hash uint64
+	for bit, count := range counts {
+		if count > 0 {
+			hash |= 1 << bit
+		}
+	}
+
+	return hash
+}
+
+// Distance - расстояние Хэмминга между отпечатками
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
@@ -0,0 +1,79 @@
+package fingerprint
+
+import (
+	"strings"
+	"testing"
+)
+
+const text = "the crawler visits pages of the web and stores their text for later search " +
+	"it keeps versions of every page so users can see what changed between two launches of one task " +
+	"and the crawler also builds a link graph of sources with page rank and hits scores for ranking"
+
+const unrelated = "recipes for a tasty soup need fresh vegetables some salt and a lot of patience " +
+	"while the pot boils slowly on the stove in a quiet kitchen during a cold winter evening with friends"
+
+func TestDistance(t *testing.T) {
+	tokens := strings.Fields(text)
+
+	edited := append([]string(nil), tokens...)
+	edited[len(edited)/2] = "robot"
+
+	tests := []struct {
+		name        string
+		a, b        []string
+		minDistance int
+		maxDistance int
+	}{
+		{"identical token streams", tokens, append([]string(nil), tokens...), 0, 0},
+		{"one word edit", tokens, edited, 1, 8},
+		{"unrelated texts", tokens, strings.Fields(unrelated), 20, 64},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Distance(SimHash(tt.a), SimHash(tt.b))
+			if d < tt.minDistance || d > tt.maxDistance {
+				t.Fatalf("distance %d, want between %d and %d", d, tt.minDistance, tt.maxDistance)
+			}
+		})
+	}
+}
+
+func TestShortTexts(t *testing.T) {
+	if SimHash(nil) != 0 {
+		t.Fatal("empty text must have zero fingerprint")
+	}
+
+	// Текст короче шингла хешируется целиком
+	for _, tokens := range [][]string{{"crawler"}, {"web", "crawler"}} {
+		if SimHash(tokens) == 0 {
+			t.Fatalf("zero fingerprint for %q", tokens)
+		}
+
+		if SimHash(tokens) != SimHash(append([]string(nil), tokens...)) {
+			t.Fatalf("fingerprint of %q is not stable", tokens)
+		}
+	}
+
+	if SimHash([]string{"web", "crawler"}) == SimHash([]string{"web", "robot"}) {
+		t.Fatal("different short texts must have different fingerprints")
+	}
+}
+
+func TestDistanceIsHamming(t *testing.T) {
+	tests := []struct {
+		a, b uint64
+		want int
+	}{
+		{0, 0, 0},
+		{0, 1, 1},
+		{0b1010, 0b0101, 4},
+		{0, ^uint64(0), 64},
+	}
+
+	for _, tt := range tests {
+		if got := Distance(tt.a, tt.b); got != tt.want {
+			t.Fatalf("Distance(%b, %b) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package highlighter
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+const (
+	// Количество символов контекста вокруг совпадения
+	snippetRadius = 80
+	maxSnippets   = 3
+
+	// Слова запроса обрезаются до основы, чтобы находить словоформы
+	minStemLen = 4
+
+	openTag  = "<b>"
+	closeTag = "</b>"
+)
+
+// Highlighter выделяет слова поискового запроса в тексте источника
+type Highlighter struct {
+	terms []term
+}
+
+type term struct {
+	stem  string
+	exact bool // короткие слова ищутся только целиком
+}
+
+func New(query string) *Highlighter {
+	terms := make([]term, 0)
+
+	for _, word := range words(query) {
+		// "or" - оператор websearch_to_tsquery, а не слово запроса
+		if word == "or" {
+			continue
+		}
+
+		runes := []rune(word)
+		if len(runes) <= minStemLen {
+			terms = append(terms, term{stem: word, exact: true})
+			continue
+		}
+
+		terms = append(terms, term{stem: string(runes[:max(minStemLen, len(runes)-2)])})
+	}
+
+	return &Highlighter{terms}
+}
+
+type span struct {
+	start, end int
+}
+
+// Snippets возвращает фрагменты текста вокруг совпадений с выделенными словами запроса
+func (h *Highlighter) Snippets(text string) []string {
+	runes := []rune(text)
+
+	matches := make([]span, 0)
+	for _, word := range wordSpans(runes) {
+		if h.match(strings.ToLower(string(runes[word.start:word.end]))) {
+			matches = append(matches, word)
+		}
+	}
+
+	if len(matches) == 0 {
+		return []string{}
+	}
+
+	snippets := make([]string, 0, maxSnippets)
+	for _, window := range windows(matches, len(runes)) {
+		if len(snippets) == maxSnippets {
+			break
+		}
+
+		snippets = append(snippets, render(runes, window, matches))
+	}
+
+	return snippets
+}
+
+func (h *Highlighter) match(word string) bool {
+	for _, t := range h.terms {
+		if t.exact && word == t.stem || !t.exact && strings.HasPrefix(word, t.stem) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// windows объединяет пересекающиеся окна вокруг совпадений
+func windows(matches []span, size int) []span {
+	res := make([]span, 0)
+
+	for _, m := range matches {
+		w := span{max(0, m.start-snippetRadius), min(size, m.end+snippetRadius)}
+
+		if len(res) > 0 && w.start <= res[len(res)-1].end {
+			res[len(res)-1].end = w.end
+			continue
+		}
+
+		res = append(res, w)
+	}
+
+	return res
+}
+
+func render(runes []rune, window span, matches []span) string {
+	var sb strings.Builder
+
+	if window.start > 0 {
+		sb.WriteString("… ")
+	}
+
+	pos := window.start
+	for _, m := range matches {
+		if m.end <= window.start || m.start >= window.end {
+			continue
+		}
+
+		sb.WriteString(html.EscapeString(string(runes[pos:m.start])))
+		sb.WriteString(openTag)
+		sb.WriteString(html.EscapeString(string(runes[m.start:m.end])))
+		sb.WriteString(closeTag)
+		pos = m.end
+	}
+
+	sb.WriteString(html.EscapeString(string(runes[pos:window.end])))
+
+	if window.end < len(runes) {
+		sb.WriteString(" …")
+	}
+
+	// Текст страницы содержит много переносов и отступов из разметки
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
+
+func wordSpans(runes []rune) []span {
+	res := make([]span, 0)
+
+	start := -1
+	for i, r := range runes {
+		if isWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+
+		if start >= 0 {
+			res = append(res, span{start, i})
+			start = -1
+		}
+	}
+
+	if start >= 0 {
+		res = append(res, span{start, len(runes)})
+	}
+
+	return res
+}
+
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !isWordRune(r)
+	})
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -8,15 +8,18 @@ import (
 )
 
 type Crawler interface {
-	Start(ctx context.Context, task task.Task) (map[string]*page.PageWithParentURL, error)
+	// Start возвращает загруженные страницы и количество пропущенных URL по причинам
+	Start(ctx context.Context, task task.Task, known map[string]page.Known) (map[string]*page.PageWithParentURL, map[page.SkipReason]int64, error)
 }
 
-type CollectionCollector interface {
+// Scorer - функция ранжирования страниц коллекции относительно запроса задачи
+type Scorer interface {
 	AddPage(url string, page page.Page)
-	BM25(url string) (float64, bool)
+	Score(url string) (float64, bool)
 }
 
 type Launcher interface {
 	Start(ctx context.Context, taskID int64) (int64, error)
 	Finish(ctx context.Context, params LaunhToFinishParams) error
+	Summarize(ctx context.Context, launchID int64) error
 }
@@ -0,0 +1,146 @@
+package launcher
+
+import (
+	page_models "github.com/K1flar/crawlers/internal/models/page"
+	"github.com/K1flar/crawlers/internal/models/source"
+	"github.com/K1flar/crawlers/internal/services/link_graph"
+	"github.com/K1flar/crawlers/internal/services/url_normalizer"
+	"github.com/samber/lo"
+)
+
+// edge - ссылка с доступной страницы запуска. To - URL загруженной страницы или нормализованный адрес ссылки,
+// если по ней не переходили
+type edge struct {
+	From string
+	To   string
+	Link page_models.Link
+}
+
+// linkGraph собирает все найденные ссылки доступных страниц запуска, а не только ссылки дерева обхода
+func linkGraph(pages map[string]*page_models.PageWithParentURL) []edge {
+	urlByKey := make(map[string]string, len(pages))
+	for url, page := range pages {
+		if !isAvailable(page.Page) {
+			continue
+		}
+
+		if key, err := url_normalizer.Key(url); err == nil {
+			urlByKey[key] = url
+		}
+	}
+
+	res := make([]edge, 0)
+	seen := make(map[[2]string]struct{})
+
+	add := func(from, to string, link page_models.Link) {
+		if from == to {
+			return
+		}
+
+		if _, ok := seen[[2]string{from, to}]; ok {
+			return
+		}
+
+		seen[[2]string{from, to}] = struct{}{}
+		res = append(res, edge{From: from, To: to, Link: link})
+	}
+
+	for url, page := range pages {
+		if !isAvailable(page.Page) {
+			continue
+		}
+
+		for _, link := range page.Links {
+			to, err := url_normalizer.Normalize(link.URL)
+			if err != nil {
+				continue
+			}
+
+			// Загруженная страница могла быть сохранена под адресом после редиректа или с другой схемой
+			if key, err := url_normalizer.Key(to); err == nil {
+				if crawled, ok := urlByKey[key]; ok {
+					to = crawled
+				}
+			}
+
+			add(url, to, link)
+		}
+	}
+
+	// Ссылка, по которой страница найдена, могла вести на адрес до редиректа. Такие ребра добавляются
+	// после ссылок страниц, чтобы при совпадении оставалась ссылка со страницы, а не зависело от порядка обхода
+	for url, page := range pages {
+		if !isAvailable(page.Page) || page.ParentURL == nil {
+			continue
+		}
+
+		if parent, ok := pages[*page.ParentURL]; ok && isAvailable(parent.Page) {
+			add(*page.ParentURL, url, lo.FromPtr(page.Link))
+		}
+	}
+
+	return res
+}
+
+// graphScores считает PageRank и HITS страниц urls по полному графу найденных ссылок,
+// в который входят и страницы, по ссылкам на которые краулер не переходил
+func graphScores(urls []string, edges []edge) map[string]source.GraphScores {
+	nodes := make([]string, 0, len(urls)+len(edges))
+	nodes = append(nodes, urls...)
+	for _, e := range edges {
+		nodes = append(nodes, e.To)
+	}
+
+	graph := link_graph.New(nodes)
+	for _, e := range edges {
+		graph.AddEdge(e.From, e.To)
+	}
+
+	pageRank := graph.PageRank()
+	hubs, authorities := graph.HITS()
+
+	res := make(map[string]source.GraphScores, len(urls))
+	for _, url := range urls {
+		res[url] = source.GraphScores{
+			PageRank:  pageRank[url],
+			Hub:       hubs[url],
+			Authority: authorities[url],
+		}
+	}
+
+	return res
+}
+
+// combineWeight усиливает текстовую релевантность оценками графа, приведенными к [0, 1]
+// относительно лучшей страницы запуска
+func combineWeight(
+	weight float64,
+	scores source.GraphScores,
+	maxScores source.GraphScores,
+	pageRankWeight float64,
+	authorityWeight float64,
+) float64 {
+	boost := 1.0
+
+	if maxScores.PageRank > 0 {
+		boost += pageRankWeight * scores.PageRank / maxScores.PageRank
+	}
+
+	if maxScores.Authority > 0 {
+		boost += authorityWeight * scores.Authority / maxScores.Authority
+	}
+
+	return weight * boost
+}
+
+func maxGraphScores(scores map[string]source.GraphScores) source.GraphScores {
+	var res source.GraphScores
+
+	for _, s := range scores {
+		res.PageRank = max(res.PageRank, s.PageRank)
+		res.Hub = max(res.Hub, s.Hub)
+		res.Authority = max(res.Authority, s.Authority)
+	}
+
+	return res
+}
@@ -0,0 +1,90 @@
+package launcher
+
+import (
+	"sort"
+	"testing"
+
+	page_models "github.com/K1flar/crawlers/internal/models/page"
+)
+
+func available(url string, parent *string, links ...page_models.Link) *page_models.PageWithParentURL {
+	return &page_models.PageWithParentURL{
+		ParentURL: parent,
+		Page: &page_models.Page{
+			URL:    url,
+			Status: page_models.StatusAvailable,
+			Links:  links,
+		},
+	}
+}
+
+func TestLinkGraphKeepsEveryDiscoveredEdge(t *testing.T) {
+	root := "https://example.com/"
+
+	pages := map[string]*page_models.PageWithParentURL{
+		root: available(root, nil,
+			page_models.Link{URL: "http://Example.com/a/", Text: "A", Rel: []string{"nofollow"}, Snippet: "see A"},
+			page_models.Link{URL: "https://other.org/x?utm_source=feed", Text: "X"},
+			page_models.Link{URL: "https://example.com/", Text: "self"},
+			page_models.Link{URL: "://bad"},
+		),
+		"https://example.com/a": available("https://example.com/a", &root,
+			page_models.Link{URL: "https://other.org/x", Text: "X again"},
+		),
+	}
+
+	got := linkGraph(pages)
+
+	sort.Slice(got, func(i, j int) bool {
+		if got[i].From != got[j].From {
+			return got[i].From < got[j].From
+		}
+
+		return got[i].To < got[j].To
+	})
+
+	want := []edge{
+		{From: "https://example.com/", To: "https://example.com/a", Link: page_models.Link{URL: "http://Example.com/a/", Text: "A", Rel: []string{"nofollow"}, Snippet: "see A"}},
+		{From: "https://example.com/", To: "https://other.org/x", Link: page_models.Link{URL: "https://other.org/x?utm_source=feed", Text: "X"}},
+		{From: "https://example.com/a", To: "https://other.org/x", Link: page_models.Link{URL: "https://other.org/x", Text: "X again"}},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d edges %+v, want %d", len(got), got, len(want))
+	}
+
+	for i := range want {
+		if got[i].From != want[i].From || got[i].To != want[i].To || got[i].Link.Text != want[i].Link.Text ||
+			got[i].Link.Snippet != want[i].Link.Snippet || len(got[i].Link.Rel) != len(want[i].Link.Rel) {
+			t.Fatalf("edge %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestGraphScoresUseUncrawledPages(t *testing.T) {
+	a, b := "https://example.com/a", "https://example.com/b"
+
+	// Без внешних ссылок страницы симметричны, внешние ссылки на b делают ее хабом
+	edges := []edge{
+		{From: a, To: b},
+		{From: b, To: a},
+		{From: b, To: "https://other.org/1"},
+		{From: b, To: "https://other.org/2"},
+	}
+
+	scores := graphScores([]string{a, b}, edges)
+
+	if len(scores) != 2 {
+		t.Fatalf("got scores for %d pages, want only crawled pages", len(scores))
+	}
+
+	if scores[b].Hub <= scores[a].Hub {
+		t.Fatalf("hub of page with external links %v must exceed %v", scores[b].Hub, scores[a].Hub)
+	}
+
+	// Часть веса b уходит по ссылкам на внешние страницы, а не на a
+	crawledOnly := graphScores([]string{a, b}, edges[:2])
+	if scores[a].PageRank >= crawledOnly[a].PageRank {
+		t.Fatalf("page rank of %s %v must drop below %v when %s links to external pages", a, scores[a].PageRank, crawledOnly[a].PageRank, b)
+	}
+}
@@ -1,19 +1,26 @@
 package launcher
 
 import (
+	"cmp"
+	"container/heap"
 	"context"
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"time"
 
+	"github.com/K1flar/crawlers/internal/gates"
 	"github.com/K1flar/crawlers/internal/models/launch"
 	"github.com/K1flar/crawlers/internal/models/page"
 	page_models "github.com/K1flar/crawlers/internal/models/page"
 	"github.com/K1flar/crawlers/internal/models/source"
 	"github.com/K1flar/crawlers/internal/models/task"
 	"github.com/K1flar/crawlers/internal/services"
-	"github.com/K1flar/crawlers/internal/services/collection_collector"
+	"github.com/K1flar/crawlers/internal/services/analyzer"
+	"github.com/K1flar/crawlers/internal/services/fingerprint"
+	"github.com/K1flar/crawlers/internal/services/scorer"
+	"github.com/K1flar/crawlers/internal/services/url_normalizer"
 	"github.com/K1flar/crawlers/internal/storage"
 	"github.com/K1flar/crawlers/internal/utils"
 	"github.com/samber/lo"
@@ -21,11 +28,15 @@ import (
 )
 
 type Service struct {
-	log         *slog.Logger
-	launches    storage.Launches
-	taskSources storage.TaskSources
-	sources     storage.Sources
-	now         func() time.Time
+	log            *slog.Logger
+	launches       storage.Launches
+	taskSources    storage.TaskSources
+	sources        storage.Sources
+	sourceContents storage.SourceContents
+	sourceAliases  storage.SourceAliases
+	sourceLinks    storage.SourceLinks
+	summarizer     gates.Summarizer
+	now            func() time.Time
 }
 
 func NewService(
@@ -33,13 +44,21 @@ func NewService(
 	launches storage.Launches,
 	taskSources storage.TaskSources,
 	sources storage.Sources,
+	sourceContents storage.SourceContents,
+	sourceAliases storage.SourceAliases,
+	sourceLinks storage.SourceLinks,
+	summarizer gates.Summarizer,
 ) *Service {
 	return &Service{
-		log:         log,
-		launches:    launches,
-		taskSources: taskSources,
-		sources:     sources,
-		now:         time.Now,
+		log:            log,
+		launches:       launches,
+		taskSources:    taskSources,
+		sources:        sources,
+		sourceContents: sourceContents,
+		sourceAliases:  sourceAliases,
+		sourceLinks:    sourceLinks,
+		summarizer:     summarizer,
+		now:            time.Now,
 	}
 }
 
@@ -54,25 +73,71 @@ func (s *Service) Start(ctx context.Context, taskID int64) (int64, error) {
 
 func (s *Service) Finish(ctx context.Context, params services.LaunhToFinishParams) error {
 	status := launch.StatusFinished
-	if params.Error != nil {
+	switch {
+	case params.Error != nil:
 		status = launch.StatusFailed
+	case params.Cancelled:
+		status = launch.StatusCancelled
 	}
 
+	stats, saveErr := s.saveSources(ctx, params)
+
 	err := s.launches.Finish(ctx, storage.ToFinishLaunch{
 		ID:            params.LaunchID,
 		FinishedAt:    s.now(),
 		SourcesViewed: int64(len(params.Pages)),
 		Status:        status,
 		Error:         launch.ErrorToSlug(params.Error),
+		Stats:         stats,
+		Skipped:       params.Skipped,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to finish launch: %w", err)
 	}
 
+	return saveErr
+}
+
+// Summarize сохраняет пересказ источника запуска с наибольшим весом
+func (s *Service) Summarize(ctx context.Context, launchID int64) error {
+	sources, err := s.sources.GetByLaunchID(ctx, launchID)
+	if err != nil {
+		return fmt.Errorf("failed to get launch sources: %w", err)
+	}
+
+	if len(sources) == 0 {
+		return nil
+	}
+
+	top := lo.MaxBy(sources, func(a, b source.ForTask) bool {
+		return a.Weight > b.Weight || (a.Weight == b.Weight && a.ID < b.ID)
+	})
+
+	content, err := s.sourceContents.Get(ctx, top.ID, &launchID)
+	if err != nil {
+		return fmt.Errorf("failed to get content of source [%d]: %w", top.ID, err)
+	}
+
+	summary, err := s.summarizer.Summarize(ctx, top.Title, content.Text)
+	if err != nil {
+		return fmt.Errorf("failed to summarize source [%d]: %w", top.ID, err)
+	}
+
+	if summary == "" {
+		return nil
+	}
+
+	return s.launches.SetSummary(ctx, launchID, launch.Summary{
+		SourceID: top.ID,
+		Text:     summary,
+	})
+}
+
+func (s *Service) saveSources(ctx context.Context, params services.LaunhToFinishParams) (launch.Stats, error) {
 	if len(params.Pages) == 0 {
 		s.log.Warn(fmt.Sprintf("zero pages for task [%d], launch [%d]", params.Task.ID, params.LaunchID))
 
-		return nil
+		return calculateStats(params.Known, nil, nil, nil), nil
 	}
 
 	pages := make(map[string]*page_models.PageWithParentURL, len(params.Pages))
@@ -85,16 +150,42 @@ func (s *Service) Finish(ctx context.Context, params services.LaunhToFinishParam
 		pages[url] = page
 	}
 
-	pagesWithWeight := s.calculateWeightAndFilter(pages, params.Task)
+	existedSourcesByURL, err := s.sources.GetByURLs(ctx, lo.Keys(pages))
+	if err != nil {
+		return launch.Stats{}, err
+	}
+
+	lastContents, err := s.sourceContents.GetLastBySourceIDs(ctx, lo.MapToSlice(existedSourcesByURL, func(_ string, source source.Source) int64 {
+		return source.ID
+	}))
+	if err != nil {
+		return launch.Stats{}, fmt.Errorf("failed to get last sources content: %w", err)
+	}
+
+	s.restoreNotModified(pages, existedSourcesByURL, lastContents)
 
-	toCreate, toUpdate, err := s.filterPages(ctx, pages, pagesWithWeight)
+	edges := linkGraph(pages)
+
+	pagesWithWeight, err := s.calculateWeightAndFilter(pages, edges, params.Task)
 	if err != nil {
-		return err
+		return launch.Stats{}, err
+	}
+
+	hashes := make(map[string]string, len(pages))
+	for url, page := range pages {
+		if existedSource, ok := existedSourcesByURL[url]; ok && page.Status == page_models.StatusNotModified {
+			hashes[url] = existedSource.ContentHash
+			continue
+		}
+
+		hashes[url] = utils.SHA256(page.Content)
 	}
 
+	toCreate, toUpdate := s.filterPages(pages, pagesWithWeight, existedSourcesByURL, hashes, params.LaunchID)
+
 	idByURL, err := s.createOrUpdateSources(ctx, toCreate, toUpdate)
 	if err != nil {
-		return err
+		return launch.Stats{}, err
 	}
 
 	s.log.Info(fmt.Sprintf("create %d sources, update %d sources for task %d", len(toCreate), len(toUpdate), params.Task.ID))
@@ -102,28 +193,148 @@ func (s *Service) Finish(ctx context.Context, params services.LaunhToFinishParam
 	err = s.taskSources.Create(ctx, s.makeParamsToCreateTaskSources(
 		pagesWithWeight,
 		idByURL,
-		params.Task.ID, params.LaunchID,
+		params.Task,
+		params.LaunchID,
+	))
+	if err != nil {
+		return launch.Stats{}, err
+	}
+
+	err = s.sourceContents.Create(ctx, s.makeParamsToCreateSourceContents(
+		pages,
+		pagesWithWeight,
+		idByURL,
+		hashes,
+		lastContents,
+		params.Task.Language,
+		params.LaunchID,
 	))
+	if err != nil {
+		return launch.Stats{}, fmt.Errorf("failed to save sources content: %w", err)
+	}
+
+	err = s.sourceAliases.Create(ctx, s.makeParamsToCreateSourceAliases(pagesWithWeight, idByURL, params.LaunchID))
+	if err != nil {
+		return launch.Stats{}, fmt.Errorf("failed to save sources aliases: %w", err)
+	}
+
+	err = s.sourceLinks.Create(ctx, s.makeParamsToCreateSourceLinks(pages, pagesWithWeight, edges, idByURL, params.LaunchID))
+	if err != nil {
+		return launch.Stats{}, fmt.Errorf("failed to save sources links: %w", err)
+	}
 
-	return err
+	return calculateStats(params.Known, pages, pagesWithWeight, hashes), nil
+}
+
+// restoreNotModified подставляет заголовок и текст из прошлого запуска страницам, не изменившимся с него
+func (s *Service) restoreNotModified(
+	pages map[string]*page_models.PageWithParentURL,
+	existedSourcesByURL map[string]source.Source,
+	lastContents map[int64]source.Content,
+) {
+	for url, page := range pages {
+		if page.Status != page_models.StatusNotModified {
+			continue
+		}
+
+		existedSource, ok := existedSourcesByURL[url]
+		if !ok {
+			s.log.Warn(fmt.Sprintf("not modified page %s without source", url))
+			continue
+		}
+
+		page.Title = existedSource.Title
+		page.Metadata = existedSource.Metadata
+
+		if content, ok := lastContents[existedSource.ID]; ok {
+			page.Content = content.Text
+		}
+	}
+}
+
+// calculateStats сравнивает базу знаний запуска с базой знаний прошлого запуска.
+// Каждая известная страница попадает ровно в один счетчик: незагруженная или недоступная исчезла,
+// загруженная сравнивается по хешу содержимого, даже если ее отсеяли по весу, лимиту или как дубль.
+// Новыми считаются только сохраненные в этом запуске страницы
+func calculateStats(
+	known map[string]page_models.Known,
+	pages map[string]*page_models.PageWithParentURL,
+	pagesWithWeight map[string]pageWithWeight,
+	hashes map[string]string,
+) launch.Stats {
+	var stats launch.Stats
+
+	// Страницы прошлого запуска хранятся под адресами из БД, страницы запуска - под нормализованными
+	known = normalizeKnown(known)
+
+	for url := range pagesWithWeight {
+		if _, ok := known[url]; !ok {
+			stats.New++
+		}
+	}
+
+	for url, k := range known {
+		page, ok := pages[url]
+
+		switch {
+		case !ok || !isAvailable(page.Page):
+			stats.Gone++
+		case k.ContentHash == hashes[url]:
+			stats.Unchanged++
+		default:
+			stats.Changed++
+		}
+	}
+
+	return stats
+}
+
+func normalizeKnown(known map[string]page_models.Known) map[string]page_models.Known {
+	res := make(map[string]page_models.Known, len(known))
+
+	for url, k := range known {
+		if normalized, err := url_normalizer.Normalize(url); err == nil {
+			url = normalized
+		}
+
+		res[url] = k
+	}
+
+	return res
 }
 
 type pageWithWeight struct {
 	URL       string
 	ParentURL *string
 	Weight    float64
+	Graph     source.GraphScores
+	Aliases   []alias // почти полные копии страницы, схлопнутые в нее
+}
+
+type alias struct {
+	URL      string
+	Distance int
 }
 
 func (s *Service) calculateWeightAndFilter(
 	pages map[string]*page_models.PageWithParentURL,
+	edges []edge,
 	task task.Task,
-) map[string]pageWithWeight {
-	collector := collection_collector.New(task.Query)
+) (map[string]pageWithWeight, error) {
+	lang, err := analyzer.ParseLanguage(task.Language)
+	if err != nil {
+		return nil, err
+	}
+
+	collector, err := scorer.New(task.Scorer, task.Query, task.ScorerParams, analyzer.New(lang))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scorer [%s]: %w", task.Scorer, err)
+	}
 
 	urls := make([]string, 0, len(pages))
 
 	for url, page := range pages {
-		if page.Status != page_models.StatusAvailable {
+		if !isAvailable(page.Page) {
 			continue
 		}
 
@@ -134,13 +345,24 @@ func (s *Service) calculateWeightAndFilter(
 		collector.AddPage(url, *pages[url].Page)
 	}
 
+	scores := graphScores(urls, edges)
+	maxScores := maxGraphScores(scores)
+
 	filteredPagesWithWeight := make(map[string]pageWithWeight, len(urls))
 	for _, url := range urls {
-		weight, ok := collector.BM25(url)
+		weight, ok := collector.Score(url)
 		if !ok {
 			s.log.Error(fmt.Sprintf("no weight for url %s", url))
 		}
 
+		weight = combineWeight(
+			weight,
+			scores[url],
+			maxScores,
+			lo.FromPtr(task.ScorerParams.PageRankWeight),
+			lo.FromPtr(task.ScorerParams.AuthorityWeight),
+		)
+
 		if weight < task.MinWeight {
 			continue
 		}
@@ -149,73 +371,232 @@ func (s *Service) calculateWeightAndFilter(
 			URL:       url,
 			ParentURL: pages[url].ParentURL,
 			Weight:    weight,
+			Graph:     scores[url],
 		}
 	}
 
-	return getConnectedPages(filteredPagesWithWeight, task.MaxSources)
+	if task.DuplicateDistance >= 0 {
+		filteredPagesWithWeight = collapseDuplicates(pages, filteredPagesWithWeight, task.DuplicateDistance)
+	}
+
+	return getConnectedPages(pages, filteredPagesWithWeight, task.MaxSources), nil
 }
 
-func getConnectedPages(pages map[string]pageWithWeight, maxPages int64) map[string]pageWithWeight {
-	connectivityPages := make(map[string]pageWithWeight)
+// collapseDuplicates оставляет из почти одинаковых страниц самую релевантную, остальные становятся ее псевдонимами.
+// Дочерние страницы дублей переходят к ближайшему оставшемуся предку
+func collapseDuplicates(
+	pages map[string]*page_models.PageWithParentURL,
+	pagesWithWeight map[string]pageWithWeight,
+	maxDistance int,
+) map[string]pageWithWeight {
+	urls := lo.Keys(pagesWithWeight)
+	slices.SortFunc(urls, func(a, b string) int {
+		return cmp.Or(
+			cmp.Compare(pagesWithWeight[b].Weight, pagesWithWeight[a].Weight),
+			cmp.Compare(len(a), len(b)),
+			cmp.Compare(a, b),
+		)
+	})
+
+	type canonical struct {
+		url  string
+		hash uint64
+	}
+
+	canonicals := make([]canonical, 0, len(urls))
+	duplicates := make(map[string]struct{})
 
-	var visit func(url string)
-	visit = func(url string) {
-		if len(connectivityPages) >= int(maxPages) {
-			return
+	for _, url := range urls {
+		tokens := analyzer.Tokenize(pages[url].Content)
+		if len(tokens) < fingerprint.MinTokens {
+			continue
 		}
 
-		connectivityPages[url] = pages[url]
+		hash := fingerprint.SimHash(tokens)
 
-		for u, p := range pages {
-			if p.ParentURL != nil && *p.ParentURL == url {
-				visit(u)
+		original, found := lo.Find(canonicals, func(c canonical) bool {
+			return fingerprint.Distance(c.hash, hash) <= maxDistance
+		})
+		if !found {
+			canonicals = append(canonicals, canonical{url, hash})
+			continue
+		}
+
+		page := pagesWithWeight[original.url]
+		page.Aliases = append(page.Aliases, alias{URL: url, Distance: fingerprint.Distance(original.hash, hash)})
+		pagesWithWeight[original.url] = page
+
+		duplicates[url] = struct{}{}
+		delete(pagesWithWeight, url)
+	}
+
+	if len(duplicates) == 0 {
+		return pagesWithWeight
+	}
+
+	for url, page := range pagesWithWeight {
+		parent := page.ParentURL
+		for parent != nil {
+			if _, ok := duplicates[*parent]; !ok {
+				break
 			}
+
+			parent = pages[*parent].ParentURL
 		}
+
+		page.ParentURL = parent
+		pagesWithWeight[url] = page
 	}
 
-	for url, page := range pages {
+	return pagesWithWeight
+}
+
+// getConnectedPages выбирает не более maxPages страниц так, чтобы вместе с каждой страницей был выбран ее родитель.
+// Выбор жадный: из страниц, родитель которых уже выбран, берется самая релевантная, при равном весе - менее глубокая,
+// затем - с меньшим URL. Страница, родитель которой отфильтрован, переходит к ближайшему оставшемуся предку,
+// а без него становится корнем
+func getConnectedPages(
+	pages map[string]*page_models.PageWithParentURL,
+	pagesWithWeight map[string]pageWithWeight,
+	maxPages int64,
+) map[string]pageWithWeight {
+	children := make(map[string][]string, len(pagesWithWeight))
+	roots := make([]string, 0)
+
+	for url, page := range pagesWithWeight {
+		page.ParentURL = survivingAncestor(pages, pagesWithWeight, url, page.ParentURL)
+		pagesWithWeight[url] = page
+
 		if page.ParentURL == nil {
-			visit(url)
+			roots = append(roots, url)
+			continue
+		}
+
+		children[*page.ParentURL] = append(children[*page.ParentURL], url)
+	}
+
+	connectedPages := make(map[string]pageWithWeight, min(int(maxPages), len(pagesWithWeight)))
+
+	frontier := &selection{weights: pagesWithWeight}
+	for _, url := range roots {
+		heap.Push(frontier, selectionItem{url: url, depth: 1})
+	}
+
+	for frontier.Len() > 0 && len(connectedPages) < int(maxPages) {
+		item := heap.Pop(frontier).(selectionItem)
+
+		connectedPages[item.url] = pagesWithWeight[item.url]
+
+		for _, child := range children[item.url] {
+			heap.Push(frontier, selectionItem{url: child, depth: item.depth + 1})
 		}
 	}
 
-	return connectivityPages
+	return connectedPages
 }
 
-func (s *Service) filterPages(
-	ctx context.Context,
-	pages map[string]*page.PageWithParentURL,
+// survivingAncestor поднимается по дереву обхода до первого предка, оставшегося после фильтрации
+func survivingAncestor(
+	pages map[string]*page_models.PageWithParentURL,
 	pagesWithWeight map[string]pageWithWeight,
-) ([]storage.ToCreateSource, []storage.ToUpdateSource, error) {
-	urls := lo.MapToSlice(pages, func(url string, _ *page_models.PageWithParentURL) string {
-		return url
-	})
+	url string,
+	parent *string,
+) *string {
+	seen := map[string]struct{}{url: {}}
+
+	for parent != nil {
+		if _, ok := pagesWithWeight[*parent]; ok {
+			return parent
+		}
 
-	existedSourcesByURL, err := s.sources.GetByURLs(ctx, urls)
-	if err != nil {
-		return nil, nil, err
+		if _, ok := seen[*parent]; ok {
+			return nil
+		}
+		seen[*parent] = struct{}{}
+
+		page, ok := pages[*parent]
+		if !ok {
+			return nil
+		}
+
+		parent = page.ParentURL
+	}
+
+	return nil
+}
+
+type selectionItem struct {
+	url   string
+	depth int
+}
+
+// selection - куча страниц-кандидатов для getConnectedPages
+type selection struct {
+	items   []selectionItem
+	weights map[string]pageWithWeight
+}
+
+func (s *selection) Len() int { return len(s.items) }
+
+func (s *selection) Less(i, j int) bool {
+	a, b := s.items[i], s.items[j]
+
+	if wa, wb := s.weights[a.url].Weight, s.weights[b.url].Weight; wa != wb {
+		return wa > wb
 	}
 
+	if a.depth != b.depth {
+		return a.depth < b.depth
+	}
+
+	return a.url < b.url
+}
+
+func (s *selection) Swap(i, j int) { s.items[i], s.items[j] = s.items[j], s.items[i] }
+
+func (s *selection) Push(x any) { s.items = append(s.items, x.(selectionItem)) }
+
+func (s *selection) Pop() any {
+	n := len(s.items)
+	item := s.items[n-1]
+	s.items = s.items[:n-1]
+
+	return item
+}
+
+func (s *Service) filterPages(
+	pages map[string]*page.PageWithParentURL,
+	pagesWithWeight map[string]pageWithWeight,
+	existedSourcesByURL map[string]source.Source,
+	hashes map[string]string,
+	launchID int64,
+) ([]storage.ToCreateSource, []storage.ToUpdateSource) {
 	var (
 		toUpdate []storage.ToUpdateSource
 		toCreate []storage.ToCreateSource
 	)
 
-	for _, url := range urls {
+	for url, page := range pages {
 		sourceStatus := source.StatusUnavailable
-		if pages[url].Status == page_models.StatusAvailable {
+		if isAvailable(page.Page) {
 			sourceStatus = source.StatusAvailable
 		}
 
 		if existedSource, exists := existedSourcesByURL[url]; exists {
 			toUpdate = append(toUpdate, storage.ToUpdateSource{
-				ID:        existedSource.ID,
-				Title:     pages[url].Title,
-				Status:    sourceStatus,
-				UpdatedAt: s.now(),
+				ID:           existedSource.ID,
+				URL:          url,
+				LaunchID:     launchID,
+				Title:        page.Title,
+				Status:       sourceStatus,
+				UpdatedAt:    s.now(),
+				ETag:         page.ETag,
+				LastModified: page.LastModified,
+				ContentHash:  contentHash(page.Page, existedSource, hashes[url]),
+				Metadata:     metadata(page.Page, existedSource),
 			})
 		} else {
-			if pages[url].Status != page.StatusAvailable {
+			if !isAvailable(page.Page) {
 				continue
 			}
 
@@ -224,21 +605,49 @@ func (s *Service) filterPages(
 			}
 
 			toCreate = append(toCreate, storage.ToCreateSource{
-				Title:     pages[url].Title,
-				URL:       pages[url].URL,
-				Status:    sourceStatus,
-				CreatedAt: s.now(),
+				LaunchID:     launchID,
+				Title:        page.Title,
+				URL:          page.URL,
+				Status:       sourceStatus,
+				CreatedAt:    s.now(),
+				ETag:         page.ETag,
+				LastModified: page.LastModified,
+				ContentHash:  hashes[url],
+				Metadata:     page.Metadata,
 			})
 		}
 	}
 
-	return toCreate, toUpdate, nil
+	return toCreate, toUpdate
+}
+
+// contentHash для недоступной страницы сохраняет хэш последнего известного содержимого
+func contentHash(page *page_models.Page, existedSource source.Source, hash string) string {
+	if page.Status == page_models.StatusUnavailable {
+		return existedSource.ContentHash
+	}
+
+	return hash
+}
+
+// metadata для недоступной страницы сохраняет последние известные сведения о ней
+func metadata(page *page_models.Page, existedSource source.Source) page_models.Metadata {
+	if page.Status == page_models.StatusUnavailable {
+		return existedSource.Metadata
+	}
+
+	return page.Metadata
+}
+
+func isAvailable(page *page_models.Page) bool {
+	return page.Status == page_models.StatusAvailable || page.Status == page_models.StatusNotModified
 }
 
 func (s *Service) makeParamsToCreateTaskSources(
 	pagesWithWeight map[string]pageWithWeight,
 	idByURL map[string]int64,
-	taskID, launchID int64,
+	task task.Task,
+	launchID int64,
 ) []storage.ToCreateTaskSource {
 	res := make([]storage.ToCreateTaskSource, 0, len(pagesWithWeight))
 
@@ -249,11 +658,51 @@ func (s *Service) makeParamsToCreateTaskSources(
 		}
 
 		res = append(res, storage.ToCreateTaskSource{
-			TaskID:         taskID,
+			TaskID:         task.ID,
 			LaunchID:       launchID,
 			SourceID:       idByURL[url],
 			ParentSourceID: parentID,
 			Weight:         page.Weight,
+			Scorer:         lo.CoalesceOrEmpty(task.Scorer, string(scorer.Default)),
+			Graph:          page.Graph,
+		})
+	}
+
+	return res
+}
+
+// makeParamsToCreateSourceContents сохраняет текст, по которому был посчитан вес источника,
+// если он отличается от последнего сохраненного
+func (s *Service) makeParamsToCreateSourceContents(
+	pages map[string]*page_models.PageWithParentURL,
+	pagesWithWeight map[string]pageWithWeight,
+	idByURL map[string]int64,
+	hashes map[string]string,
+	lastContents map[int64]source.Content,
+	language string,
+	launchID int64,
+) []storage.ToCreateSourceContent {
+	res := make([]storage.ToCreateSourceContent, 0, len(pagesWithWeight))
+
+	for url := range pagesWithWeight {
+		sourceID := idByURL[url]
+
+		if pages[url].Status == page_models.StatusNotModified {
+			continue
+		}
+
+		if last, ok := lastContents[sourceID]; ok && last.Hash == hashes[url] {
+			continue
+		}
+
+		res = append(res, storage.ToCreateSourceContent{
+			SourceID:  sourceID,
+			LaunchID:  launchID,
+			Title:     pages[url].Title,
+			Text:      pages[url].Content,
+			Hash:      hashes[url],
+			CreatedAt: s.now(),
+			Language:  language,
 		})
 	}
 
@@ -320,3 +769,103 @@ func (s *Service) createOrUpdateSources(
 
 	return res, nil
 }
+
+func (s *Service) makeParamsToCreateSourceAliases(
+	pagesWithWeight map[string]pageWithWeight,
+	idByURL map[string]int64,
+	launchID int64,
+) []storage.ToCreateSourceAlias {
+	res := make([]storage.ToCreateSourceAlias, 0)
+
+	for url, page := range pagesWithWeight {
+		for _, alias := range page.Aliases {
+			res = append(res, storage.ToCreateSourceAlias{
+				LaunchID:  launchID,
+				SourceID:  idByURL[url],
+				URL:       alias.URL,
+				Distance:  alias.Distance,
+				CreatedAt: s.now(),
+			})
+		}
+	}
+
+	return res
+}
+
+// makeParamsToCreateSourceLinks сохраняет все найденные ссылки источников запуска вместе с текстом,
+// rel и окружением ссылки. Ссылка на дубль привязывается к источнику, в который дубль схлопнут,
+// ссылка на страницу вне базы знаний сохраняется только с адресом.
+// Ссылки дерева обхода сохраняются всегда: если родитель оказался дублем,
+// ссылка с него переносится на оставшегося предка
+func (s *Service) makeParamsToCreateSourceLinks(
+	pages map[string]*page_models.PageWithParentURL,
+	pagesWithWeight map[string]pageWithWeight,
+	edges []edge,
+	idByURL map[string]int64,
+	launchID int64,
+) []storage.ToCreateSourceLink {
+	ownerByAlias := make(map[string]string)
+	for url, page := range pagesWithWeight {
+		for _, alias := range page.Aliases {
+			ownerByAlias[alias.URL] = url
+		}
+	}
+
+	sourceID := func(url string) (int64, bool) {
+		if id, ok := idByURL[url]; ok {
+			return id, true
+		}
+
+		id, ok := idByURL[ownerByAlias[url]]
+
+		return id, ok
+	}
+
+	res := make([]storage.ToCreateSourceLink, 0, len(edges))
+	seen := make(map[string]struct{}, len(edges))
+
+	add := func(from, to string, link page_models.Link) {
+		fromID, ok := sourceID(from)
+		if !ok {
+			return
+		}
+
+		var toID *int64
+		if id, ok := sourceID(to); ok {
+			if id == fromID {
+				return
+			}
+
+			toID = &id
+		}
+
+		key := fmt.Sprintf("%d %s", fromID, to)
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+
+		res = append(res, storage.ToCreateSourceLink{
+			LaunchID:     launchID,
+			FromSourceID: fromID,
+			ToSourceID:   toID,
+			ToURL:        to,
+			Anchor:       link.Text,
+			Rel:          link.Rel,
+			Snippet:      link.Snippet,
+			CreatedAt:    s.now(),
+		})
+	}
+
+	for url, page := range pagesWithWeight {
+		if page.ParentURL != nil {
+			add(*page.ParentURL, url, lo.FromPtr(pages[url].Link))
+		}
+	}
+
+	for _, e := range edges {
+		add(e.From, e.To, e.Link)
+	}
+
+	return res
+}
@@ -0,0 +1,356 @@
+package launcher
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/K1flar/crawlers/internal/models/launch"
+	page_models "github.com/K1flar/crawlers/internal/models/page"
+	"github.com/samber/lo"
+)
+
+// crawlTree - случайное дерево обхода: часть страниц отфильтрована по весу, у части родитель
+// не загружен, встречаются циклы через редиректы и одинаковые веса
+type crawlTree struct {
+	pages    map[string]*page_models.PageWithParentURL
+	weighted map[string]pageWithWeight
+	maxPages int64
+}
+
+func (crawlTree) Generate(r *rand.Rand, size int) reflect.Value {
+	n := r.Intn(size + 1)
+
+	tree := crawlTree{
+		pages:    make(map[string]*page_models.PageWithParentURL, n),
+		weighted: make(map[string]pageWithWeight, n),
+		maxPages: int64(r.Intn(n + 2)),
+	}
+
+	url := func(i int) string { return fmt.Sprintf("https://example.com/%d", i) }
+
+	for i := range n {
+		var parent *string
+
+		switch k := r.Intn(10); {
+		case k == 0:
+			// Родитель не попал в результаты обхода
+			parent = new(string)
+			*parent = url(n + i)
+		case k < 3 || i == 0:
+		default:
+			// Обычно родитель загружен раньше, но редиректы дают ссылки вперед и циклы
+			j := r.Intn(i)
+			if r.Intn(10) == 0 {
+				j = r.Intn(n)
+			}
+
+			parent = new(string)
+			*parent = url(j)
+		}
+
+		tree.pages[url(i)] = &page_models.PageWithParentURL{
+			ParentURL: parent,
+			Page:      &page_models.Page{URL: url(i)},
+		}
+
+		if r.Intn(4) != 0 {
+			tree.weighted[url(i)] = pageWithWeight{
+				URL:       url(i),
+				ParentURL: parent,
+				Weight:    float64(r.Intn(5)),
+			}
+		}
+	}
+
+	return reflect.ValueOf(tree)
+}
+
+// connect копирует страницы, чтобы getConnectedPages не изменила исходное дерево
+func (t crawlTree) connect() map[string]pageWithWeight {
+	weighted := make(map[string]pageWithWeight, len(t.weighted))
+	for url, page := range t.weighted {
+		weighted[url] = page
+	}
+
+	return getConnectedPages(t.pages, weighted, t.maxPages)
+}
+
+// ancestor - первый оставшийся после фильтрации предок страницы, найденный перебором
+func (t crawlTree) ancestor(url string) (string, bool) {
+	page := t.pages[url]
+
+	for range len(t.pages) + 1 {
+		if page.ParentURL == nil {
+			return "", false
+		}
+
+		parent := *page.ParentURL
+		if _, ok := t.weighted[parent]; ok {
+			return parent, true
+		}
+
+		if page = t.pages[parent]; page == nil {
+			return "", false
+		}
+	}
+
+	// Цикл из отфильтрованных страниц
+	return "", false
+}
+
+// reachable - оставшиеся страницы, от которых по оставшимся предкам можно дойти до корня
+func (t crawlTree) reachable() map[string]struct{} {
+	res := make(map[string]struct{})
+
+	for url := range t.weighted {
+		cur := url
+
+		for range len(t.weighted) + 1 {
+			parent, ok := t.ancestor(cur)
+			if !ok {
+				res[url] = struct{}{}
+				break
+			}
+
+			cur = parent
+		}
+	}
+
+	return res
+}
+
+func TestGetConnectedPagesKeepsLimit(t *testing.T) {
+	f := func(tree crawlTree) bool {
+		return len(tree.connect()) <= int(tree.maxPages)
+	}
+
+	if err := quick.Check(f, &quick.Config{MaxCount: 500}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetConnectedPagesKeepsAncestorChains(t *testing.T) {
+	f := func(tree crawlTree) bool {
+		kept := tree.connect()
+
+		for url, page := range kept {
+			// Родителем становится ближайший оставшийся предок
+			want, ok := tree.ancestor(url)
+			if ok != (page.ParentURL != nil) || ok && *page.ParentURL != want {
+				t.Logf("page %s: parent %v, want %s", url, page.ParentURL, want)
+				return false
+			}
+
+			// Цепочка предков выбранной страницы выбрана целиком и заканчивается корнем
+			cur := page
+			for steps := 0; cur.ParentURL != nil; steps++ {
+				parent, ok := kept[*cur.ParentURL]
+				if !ok || steps > len(kept) {
+					t.Logf("page %s: ancestor %s is not kept", url, *cur.ParentURL)
+					return false
+				}
+
+				cur = parent
+			}
+		}
+
+		return true
+	}
+
+	if err := quick.Check(f, &quick.Config{MaxCount: 500}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetConnectedPagesFillsLimit(t *testing.T) {
+	f := func(tree crawlTree) bool {
+		kept := tree.connect()
+		reachable := tree.reachable()
+
+		return len(kept) == min(int(tree.maxPages), len(reachable))
+	}
+
+	if err := quick.Check(f, &quick.Config{MaxCount: 500}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMakeParamsToCreateSourceLinksKeepsEveryEdge(t *testing.T) {
+	s := &Service{now: time.Now}
+
+	root, child, dup := "https://example.com/", "https://example.com/a", "https://example.com/a-copy"
+
+	pages := map[string]*page_models.PageWithParentURL{
+		root:  {Page: &page_models.Page{URL: root}},
+		child: {ParentURL: &root, Link: &page_models.Link{Text: "A"}, Page: &page_models.Page{URL: child}},
+	}
+
+	pagesWithWeight := map[string]pageWithWeight{
+		root:  {URL: root},
+		child: {URL: child, ParentURL: &root, Aliases: []alias{{URL: dup}}},
+	}
+
+	edges := []edge{
+		{From: root, To: child, Link: page_models.Link{Text: "A"}},
+		{From: root, To: dup, Link: page_models.Link{Text: "copy", Rel: []string{"nofollow"}, Snippet: "around"}},
+		{From: root, To: "https://other.org/x", Link: page_models.Link{Text: "X", Snippet: "external"}},
+		{From: dup, To: root, Link: page_models.Link{Text: "home"}},
+		{From: child, To: dup, Link: page_models.Link{Text: "self"}},
+		{From: "https://example.com/filtered", To: root},
+	}
+
+	idByURL := map[string]int64{root: 1, child: 2}
+
+	got := s.makeParamsToCreateSourceLinks(pages, pagesWithWeight, edges, idByURL, 10)
+
+	type link struct {
+		from    int64
+		to      int64
+		url     string
+		anchor  string
+		snippet string
+	}
+
+	want := []link{
+		{1, 2, child, "A", ""},
+		{1, 2, dup, "copy", "around"},
+		{1, 0, "https://other.org/x", "X", "external"},
+		{2, 1, root, "home", ""},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d links %+v, want %d", len(got), got, len(want))
+	}
+
+	for i, w := range want {
+		g := got[i]
+
+		to := int64(0)
+		if g.ToSourceID != nil {
+			to = *g.ToSourceID
+		}
+
+		if (link{g.FromSourceID, to, g.ToURL, g.Anchor, g.Snippet}) != w || g.LaunchID != 10 {
+			t.Fatalf("link %d: got %+v, want %+v", i, g, w)
+		}
+	}
+}
+
+func TestCalculateStats(t *testing.T) {
+	fetched := func(status page_models.Status, urls ...string) map[string]*page_models.PageWithParentURL {
+		pages := make(map[string]*page_models.PageWithParentURL, len(urls))
+		for _, url := range urls {
+			pages[url] = &page_models.PageWithParentURL{Page: &page_models.Page{URL: url, Status: status}}
+		}
+
+		return pages
+	}
+
+	available := func(urls ...string) map[string]*page_models.PageWithParentURL {
+		return fetched(page_models.StatusAvailable, urls...)
+	}
+
+	weighted := func(urls ...string) map[string]pageWithWeight {
+		pages := make(map[string]pageWithWeight, len(urls))
+		for _, url := range urls {
+			pages[url] = pageWithWeight{URL: url}
+		}
+
+		return pages
+	}
+
+	known := func(hashByURL map[string]string) map[string]page_models.Known {
+		return lo.MapValues(hashByURL, func(hash string, _ string) page_models.Known {
+			return page_models.Known{ContentHash: hash}
+		})
+	}
+
+	tests := []struct {
+		name     string
+		known    map[string]page_models.Known
+		pages    map[string]*page_models.PageWithParentURL
+		weighted map[string]pageWithWeight
+		hashes   map[string]string
+		want     launch.Stats
+	}{
+		{
+			name:     "new, changed and unchanged",
+			known:    known(map[string]string{"http://a.com/1": "h1", "http://a.com/2": "h2"}),
+			pages:    available("http://a.com/1", "http://a.com/2", "http://a.com/3"),
+			weighted: weighted("http://a.com/1", "http://a.com/2", "http://a.com/3"),
+			hashes:   map[string]string{"http://a.com/1": "h1", "http://a.com/2": "changed", "http://a.com/3": "h3"},
+			want:     launch.Stats{New: 1, Changed: 1, Unchanged: 1},
+		},
+		{
+			name:     "known url stored before normalization",
+			known:    known(map[string]string{"HTTP://A.com:80/1/?utm_source=x": "h1"}),
+			pages:    available("http://a.com/1"),
+			weighted: weighted("http://a.com/1"),
+			hashes:   map[string]string{"http://a.com/1": "h1"},
+			want:     launch.Stats{Unchanged: 1},
+		},
+		{
+			name:     "not modified page keeps stored hash",
+			known:    known(map[string]string{"http://a.com/1": "h1"}),
+			pages:    fetched(page_models.StatusNotModified, "http://a.com/1"),
+			weighted: weighted("http://a.com/1"),
+			hashes:   map[string]string{"http://a.com/1": "h1"},
+			want:     launch.Stats{Unchanged: 1},
+		},
+		{
+			name:     "filtered out by weight, limit or as duplicate is compared by hash",
+			known:    known(map[string]string{"http://a.com/1": "h1", "http://a.com/2": "h2", "http://a.com/3": "h3"}),
+			pages:    available("http://a.com/1", "http://a.com/2", "http://a.com/3"),
+			weighted: weighted("http://a.com/1"),
+			hashes:   map[string]string{"http://a.com/1": "h1", "http://a.com/2": "h2", "http://a.com/3": "changed"},
+			want:     launch.Stats{Unchanged: 2, Changed: 1},
+		},
+		{
+			name:     "unavailable is gone",
+			known:    known(map[string]string{"http://a.com/1": "h1", "http://a.com/2": "h2"}),
+			pages:    lo.Assign(available("http://a.com/1"), fetched(page_models.StatusUnavailable, "http://a.com/2")),
+			weighted: weighted("http://a.com/1"),
+			hashes:   map[string]string{"http://a.com/1": "h1", "http://a.com/2": ""},
+			want:     launch.Stats{Unchanged: 1, Gone: 1},
+		},
+		{
+			name:     "not fetched is gone",
+			known:    known(map[string]string{"http://a.com/1": "h1", "http://a.com/2": "h2"}),
+			pages:    available("http://a.com/1"),
+			weighted: weighted("http://a.com/1"),
+			hashes:   map[string]string{"http://a.com/1": "h1"},
+			want:     launch.Stats{Unchanged: 1, Gone: 1},
+		},
+		{
+			name:     "unknown filtered page is not counted",
+			pages:    available("http://a.com/1", "http://a.com/2"),
+			weighted: weighted("http://a.com/1"),
+			hashes:   map[string]string{"http://a.com/1": "h1", "http://a.com/2": "h2"},
+			want:     launch.Stats{New: 1},
+		},
+		{
+			name:  "zero pages",
+			known: known(map[string]string{"http://a.com/1": "", "http://a.com/1/": "", "http://a.com/2": ""}),
+			want:  launch.Stats{Gone: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := calculateStats(tt.known, tt.pages, tt.weighted, tt.hashes)
+			if got != tt.want {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+
+			// Каждая известная страница попадает ровно в один счетчик
+			counted := got.Changed + got.Unchanged + got.Gone
+			if want := int64(len(normalizeKnown(tt.known))); counted != want {
+				t.Fatalf("known pages counted %d times, want %d", counted, want)
+			}
+		})
+	}
+}
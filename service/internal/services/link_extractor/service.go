@@ -0,0 +1,151 @@
+package link_extractor
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/K1flar/crawlers/internal/models/page"
+	"github.com/PuerkitoBio/goquery"
+)
+
+const (
+	maxSnippetLen = 200
+
+	snippetSelector = "p, li, td, th, dd, dt, blockquote, figcaption, caption, h1, h2, h3, h4, h5, h6"
+)
+
+// Extract возвращает ссылки страницы с абсолютными адресами. Относительные ссылки разрешаются
+// относительно <base href> или адреса страницы, ссылки не на веб-страницы пропускаются.
+// Повторные ссылки на один адрес объединяются, текст берется из первой непустой
+func Extract(doc *goquery.Document, base *url.URL) []page.Link {
+	if href, ok := doc.Find("base[href]").First().Attr("href"); ok {
+		if ref, err := url.Parse(strings.TrimSpace(href)); err == nil {
+			base = base.ResolveReference(ref)
+		}
+	}
+
+	links := make([]page.Link, 0)
+	indexByURL := make(map[string]int)
+
+	doc.Find("a[href], area[href]").Each(func(_ int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+
+		abs, ok := Resolve(base, href)
+		if !ok {
+			return
+		}
+
+		text := anchorText(s)
+
+		if i, ok := indexByURL[abs]; ok {
+			if links[i].Text == "" {
+				links[i].Text = text
+			}
+			return
+		}
+
+		indexByURL[abs] = len(links)
+		links = append(links, page.Link{
+			URL:     abs,
+			Text:    text,
+			Rel:     rel(s),
+			Snippet: snippet(s, text),
+		})
+	})
+
+	return links
+}
+
+func anchorText(s *goquery.Selection) string {
+	text := strings.Join(strings.Fields(s.Text()), " ")
+	if text != "" {
+		return text
+	}
+
+	for _, attr := range []string{"title", "aria-label", "alt"} {
+		if v, ok := s.Attr(attr); ok && strings.TrimSpace(v) != "" {
+			return strings.TrimSpace(v)
+		}
+	}
+
+	if alt, ok := s.Find("img[alt]").First().Attr("alt"); ok {
+		return strings.TrimSpace(alt)
+	}
+
+	return ""
+}
+
+// Resolve разрешает ссылку относительно base и проверяет, что она ведет на веб-страницу
+func Resolve(base *url.URL, href string) (string, bool) {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "#") {
+		return "", false
+	}
+
+	ref, err := url.Parse(href)
+	if err != nil {
+		return "", false
+	}
+
+	abs := ref
+	if base != nil {
+		abs = base.ResolveReference(ref)
+	}
+
+	// mailto:, tel:, javascript:, data: и прочие схемы
+	if abs.Scheme != "http" && abs.Scheme != "https" {
+		return "", false
+	}
+
+	if abs.Hostname() == "" {
+		return "", false
+	}
+
+	abs.Fragment = ""
+	abs.RawFragment = ""
+
+	return abs.String(), true
+}
+
+// Valid проверяет абсолютный адрес веб-страницы
+func Valid(raw string) bool {
+	_, ok := Resolve(nil, raw)
+	return ok
+}
+
+func rel(s *goquery.Selection) []string {
+	v, _ := s.Attr("rel")
+
+	return strings.Fields(strings.ToLower(v))
+}
+
+// snippet возвращает текст ближайшего блока со ссылкой, длинный текст обрезается вокруг ссылки
+func snippet(s *goquery.Selection, text string) string {
+	block := s.Closest(snippetSelector)
+	if block.Length() == 0 {
+		block = s.Parent()
+	}
+
+	words := strings.Fields(block.Text())
+	if len(words) == 0 {
+		return ""
+	}
+
+	full := []rune(strings.Join(words, " "))
+	if len(full) <= maxSnippetLen {
+		return string(full)
+	}
+
+	pos := 0
+	if text != "" {
+		if i := strings.Index(string(full), text); i >= 0 {
+			pos = len([]rune(string(full)[:i]))
+		}
+	}
+
+	start := max(0, pos-maxSnippetLen/2)
+	end := min(len(full), start+maxSnippetLen)
+	start = max(0, end-maxSnippetLen)
+
+	return strings.TrimSpace(string(full[start:end]))
+}
@@ -0,0 +1,187 @@
+package link_graph
+
+import (
+	"math"
+	"slices"
+)
+
+const (
+	damping       = 0.85
+	maxIterations = 100
+	tolerance     = 1e-9
+)
+
+// Graph - ориентированный граф ссылок между страницами.
+// Страницы пронумерованы по порядку URL, а смежность хранится отсортированными списками, чтобы
+// суммы считались в одном порядке и равные оценки не менялись местами от запуска к запуску
+type Graph struct {
+	nodes []string
+	index map[string]int
+	out   [][]int
+	in    [][]int
+}
+
+func New(nodes []string) *Graph {
+	nodes = slices.Compact(slices.Sorted(slices.Values(nodes)))
+
+	g := &Graph{
+		nodes: nodes,
+		index: make(map[string]int, len(nodes)),
+		out:   make([][]int, len(nodes)),
+		in:    make([][]int, len(nodes)),
+	}
+
+	for i, node := range nodes {
+		g.index[node] = i
+	}
+
+	return g
+}
+
+// AddEdge добавляет ссылку; ссылки на себя и на страницы вне графа пропускаются
+func (g *Graph) AddEdge(from, to string) {
+	i, ok := g.index[from]
+	if !ok {
+		return
+	}
+
+	j, ok := g.index[to]
+	if !ok || i == j {
+		return
+	}
+
+	g.out[i] = insertSorted(g.out[i], j)
+	g.in[j] = insertSorted(g.in[j], i)
+}
+
+func insertSorted(values []int, v int) []int {
+	i, found := slices.BinarySearch(values, v)
+	if found {
+		return values
+	}
+
+	return slices.Insert(values, i, v)
+}
+
+// PageRank считает вероятность попасть на страницу при случайном блуждании по ссылкам.
+// Вес страниц без исходящих ссылок распределяется поровну между всеми страницами
+func (g *Graph) PageRank() map[string]float64 {
+	n := len(g.nodes)
+	if n == 0 {
+		return map[string]float64{}
+	}
+
+	rank := fill(n, 1/float64(n))
+	next := make([]float64, n)
+
+	for range maxIterations {
+		dangling := 0.0
+		for i := range n {
+			if len(g.out[i]) == 0 {
+				dangling += rank[i]
+			}
+		}
+
+		base := (1-damping)/float64(n) + damping*dangling/float64(n)
+
+		for j := range n {
+			sum := 0.0
+			for _, i := range g.in[j] {
+				sum += rank[i] / float64(len(g.out[i]))
+			}
+
+			next[j] = base + damping*sum
+		}
+
+		delta := 0.0
+		for i := range n {
+			delta += math.Abs(next[i] - rank[i])
+		}
+
+		rank, next = next, rank
+
+		if delta < tolerance {
+			break
+		}
+	}
+
+	return g.toMap(rank)
+}
+
+// HITS считает оценки хабов (страниц со ссылками на авторитетные) и авторитетов
+// (страниц, на которые ссылаются хорошие хабы)
+func (g *Graph) HITS() (hubs map[string]float64, authorities map[string]float64) {
+	n := len(g.nodes)
+	if n == 0 {
+		return map[string]float64{}, map[string]float64{}
+	}
+
+	hub := fill(n, 1)
+	auth := make([]float64, n)
+
+	for range maxIterations {
+		for j := range n {
+			auth[j] = 0
+			for _, i := range g.in[j] {
+				auth[j] += hub[i]
+			}
+		}
+		normalize(auth)
+
+		prev := append([]float64(nil), hub...)
+
+		for i := range n {
+			hub[i] = 0
+			for _, j := range g.out[i] {
+				hub[i] += auth[j]
+			}
+		}
+		normalize(hub)
+
+		delta := 0.0
+		for i := range n {
+			delta += math.Abs(hub[i] - prev[i])
+		}
+
+		if delta < tolerance {
+			break
+		}
+	}
+
+	return g.toMap(hub), g.toMap(auth)
+}
+
+func (g *Graph) toMap(values []float64) map[string]float64 {
+	res := make(map[string]float64, len(values))
+	for i, v := range values {
+		res[g.nodes[i]] = v
+	}
+
+	return res
+}
+
+func fill(n int, v float64) []float64 {
+	res := make([]float64, n)
+	for i := range res {
+		res[i] = v
+	}
+
+	return res
+}
+
+// normalize приводит вектор к единичной длине
+func normalize(values []float64) {
+	norm := 0.0
+	for _, v := range values {
+		norm += v * v
+	}
+
+	if norm == 0 {
+		return
+	}
+
+	norm = math.Sqrt(norm)
+	for i := range values {
+		values[i] /= norm
+	}
+}
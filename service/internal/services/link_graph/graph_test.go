@@ -0,0 +1,172 @@
+package link_graph
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+type edge struct {
+	from, to string
+}
+
+func build(nodes []string, edges []edge) *Graph {
+	g := New(nodes)
+	for _, e := range edges {
+		g.AddEdge(e.from, e.to)
+	}
+
+	return g
+}
+
+func sum(values map[string]float64) float64 {
+	res := 0.0
+	for _, v := range values {
+		res += v
+	}
+
+	return res
+}
+
+func assertClose(t *testing.T, name string, got, want float64) {
+	t.Helper()
+
+	if math.Abs(got-want) > 1e-6 {
+		t.Fatalf("%s: got %v, want %v", name, got, want)
+	}
+}
+
+func TestPageRank(t *testing.T) {
+	tests := []struct {
+		name  string
+		nodes []string
+		edges []edge
+		want  map[string]float64
+	}{
+		{
+			name:  "cycle",
+			nodes: []string{"a", "b", "c"},
+			edges: []edge{{"a", "b"}, {"b", "c"}, {"c", "a"}},
+			want:  map[string]float64{"a": 1.0 / 3, "b": 1.0 / 3, "c": 1.0 / 3},
+		},
+		{
+			// Вес b без исходящих ссылок делится поровну: r(a) = 0.075 + 0.425 r(b), r(a) + r(b) = 1
+			name:  "dangling node",
+			nodes: []string{"a", "b"},
+			edges: []edge{{"a", "b"}},
+			want:  map[string]float64{"a": 0.5 / 1.425, "b": 1 - 0.5/1.425},
+		},
+		{
+			name:  "no links",
+			nodes: []string{"a", "b", "c", "d"},
+			want:  map[string]float64{"a": 0.25, "b": 0.25, "c": 0.25, "d": 0.25},
+		},
+		{
+			name:  "self links, duplicates and unknown pages are ignored",
+			nodes: []string{"a", "b", "a"},
+			edges: []edge{{"a", "a"}, {"a", "b"}, {"a", "b"}, {"a", "x"}, {"x", "b"}},
+			want:  map[string]float64{"a": 0.5 / 1.425, "b": 1 - 0.5/1.425},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rank := build(tt.nodes, tt.edges).PageRank()
+
+			if len(rank) != len(tt.want) {
+				t.Fatalf("got ranks for %d pages, want %d", len(rank), len(tt.want))
+			}
+
+			assertClose(t, "sum", sum(rank), 1)
+
+			for node, want := range tt.want {
+				assertClose(t, node, rank[node], want)
+			}
+		})
+	}
+
+	if rank := New(nil).PageRank(); len(rank) != 0 {
+		t.Fatalf("empty graph ranks %v", rank)
+	}
+}
+
+func TestPageRankSumsToOneOnRandomGraphs(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	nodes := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+
+	for range 50 {
+		edges := make([]edge, 0)
+		for range r.Intn(20) {
+			edges = append(edges, edge{nodes[r.Intn(len(nodes))], nodes[r.Intn(len(nodes))]})
+		}
+
+		assertClose(t, "sum", sum(build(nodes, edges).PageRank()), 1)
+	}
+}
+
+func TestHITS(t *testing.T) {
+	// h1 ссылается на обе страницы, h2 - только на a: a - лучший авторитет, h1 - лучший хаб
+	hubs, authorities := build(
+		[]string{"h1", "h2", "a", "b"},
+		[]edge{{"h1", "a"}, {"h1", "b"}, {"h2", "a"}},
+	).HITS()
+
+	if !(authorities["a"] > authorities["b"] && authorities["b"] > 0) {
+		t.Fatalf("unexpected authorities %v", authorities)
+	}
+
+	if authorities["h1"] != 0 || authorities["h2"] != 0 {
+		t.Fatalf("pages without incoming links are authorities: %v", authorities)
+	}
+
+	if !(hubs["h1"] > hubs["h2"] && hubs["h2"] > 0) {
+		t.Fatalf("unexpected hubs %v", hubs)
+	}
+
+	if hubs["a"] != 0 || hubs["b"] != 0 {
+		t.Fatalf("pages without outgoing links are hubs: %v", hubs)
+	}
+
+	// Оценки нормированы к единичной длине, authority = A^T hub: a = (h1 + h2), b = h1
+	norm := func(values map[string]float64) float64 {
+		res := 0.0
+		for _, v := range values {
+			res += v * v
+		}
+
+		return math.Sqrt(res)
+	}
+
+	assertClose(t, "hubs norm", norm(hubs), 1)
+	assertClose(t, "authorities norm", norm(authorities), 1)
+
+	// Для матрицы смежности этого графа главный собственный вектор A^T A пропорционален (φ, 1), φ - золотое сечение
+	assertClose(t, "authority ratio", authorities["a"]/authorities["b"], (1+math.Sqrt(5))/2)
+}
+
+func TestScoresDoNotDependOnInputOrder(t *testing.T) {
+	nodes := []string{"a", "b", "c", "d", "e", "f"}
+	edges := []edge{{"a", "b"}, {"a", "c"}, {"b", "c"}, {"c", "a"}, {"d", "c"}, {"e", "f"}, {"f", "e"}, {"d", "a"}}
+
+	wantRank := build(nodes, edges).PageRank()
+	wantHubs, wantAuthorities := build(nodes, edges).HITS()
+
+	r := rand.New(rand.NewSource(1))
+
+	for range 20 {
+		shuffledNodes := append([]string(nil), nodes...)
+		r.Shuffle(len(shuffledNodes), func(i, j int) { shuffledNodes[i], shuffledNodes[j] = shuffledNodes[j], shuffledNodes[i] })
+
+		shuffledEdges := append([]edge(nil), edges...)
+		r.Shuffle(len(shuffledEdges), func(i, j int) { shuffledEdges[i], shuffledEdges[j] = shuffledEdges[j], shuffledEdges[i] })
+
+		g := build(shuffledNodes, shuffledEdges)
+		hubs, authorities := g.HITS()
+
+		// Сравнение точное: порядок сложения не должен зависеть от порядка страниц и ссылок
+		if !reflect.DeepEqual(g.PageRank(), wantRank) || !reflect.DeepEqual(hubs, wantHubs) || !reflect.DeepEqual(authorities, wantAuthorities) {
+			t.Fatal("scores depend on input order")
+		}
+	}
+}
@@ -9,5 +9,9 @@ type LaunhToFinishParams struct {
 	LaunchID int64
 	Task     task.Task
 	Pages    map[string]*page.PageWithParentURL
+	Known    map[string]page.Known // источники прошлого запуска задачи
+	Skipped  map[page.SkipReason]int64
 	Error    error
+	// Обход прерван остановкой задачи, Pages содержит частичный результат
+	Cancelled bool
 }
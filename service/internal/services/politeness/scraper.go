@@ -0,0 +1,131 @@
+package politeness
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/K1flar/crawlers/internal/gates"
+	page_models "github.com/K1flar/crawlers/internal/models/page"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// Слишком большой Crawl-delay остановил бы обход, поэтому ограничиваем его сверху
+	maxCrawlDelay = 30 * time.Second
+)
+
+type Config struct {
+	RequestsPerSecond float64 // <= 0 - без ограничения частоты
+	Burst             int
+	MaxInFlight       int
+}
+
+type host struct {
+	limiter  *rate.Limiter
+	inFlight chan struct{}
+}
+
+// Scraper ограничивает частоту и количество одновременных запросов к каждому хосту
+// и учитывает Crawl-delay из robots.txt
+type Scraper struct {
+	scraper gates.WebScraper
+	robots  gates.RobotsTxt
+	cfg     Config
+
+	mu    sync.Mutex
+	hosts map[string]*host
+}
+
+func NewScraper(
+	scraper gates.WebScraper,
+	robots gates.RobotsTxt,
+	cfg Config,
+) *Scraper {
+	if cfg.Burst < 1 {
+		cfg.Burst = 1
+	}
+
+	if cfg.MaxInFlight < 1 {
+		cfg.MaxInFlight = 1
+	}
+
+	return &Scraper{
+		scraper: scraper,
+		robots:  robots,
+		cfg:     cfg,
+		hosts:   make(map[string]*host),
+	}
+}
+
+func (s *Scraper) GetPage(ctx context.Context, rawURL string, known *page_models.Known) (*page_models.Page, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse url [%s]: %w", rawURL, err)
+	}
+
+	h := s.host(ctx, u)
+
+	select {
+	case h.inFlight <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-h.inFlight }()
+
+	if err := h.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.scraper.GetPage(ctx, rawURL, known)
+}
+
+func (s *Scraper) host(ctx context.Context, u *url.URL) *host {
+	s.mu.Lock()
+	h, ok := s.hosts[u.Host]
+	s.mu.Unlock()
+
+	if ok {
+		return h
+	}
+
+	// robots.txt уже закэширован после проверки Allowed, поэтому запрос делаем вне блокировки
+	limit, burst := s.limit(ctx, u)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if h, ok := s.hosts[u.Host]; ok {
+		return h
+	}
+
+	h = &host{
+		limiter:  rate.NewLimiter(limit, burst),
+		inFlight: make(chan struct{}, s.cfg.MaxInFlight),
+	}
+	s.hosts[u.Host] = h
+
+	return h
+}
+
+func (s *Scraper) limit(ctx context.Context, u *url.URL) (rate.Limit, int) {
+	limit, burst := rate.Inf, s.cfg.Burst
+	if s.cfg.RequestsPerSecond > 0 {
+		limit = rate.Limit(s.cfg.RequestsPerSecond)
+	}
+
+	delay, err := s.robots.CrawlDelay(ctx, u.String())
+	if err != nil || delay <= 0 {
+		return limit, burst
+	}
+
+	delay = min(delay, maxCrawlDelay)
+
+	if every := rate.Every(delay); every < limit {
+		return every, 1
+	}
+
+	return limit, burst
+}
@@ -0,0 +1,197 @@
+package politeness
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/K1flar/crawlers/internal/gates/http_scraper"
+	"github.com/K1flar/crawlers/internal/gates/robots_txt"
+)
+
+const userAgent = "crawlers-bot/1.0"
+
+// site - тестовый сайт, запоминающий число одновременных запросов и время каждого запроса страницы
+type site struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+	hits        []time.Time
+}
+
+func newSite(t *testing.T, robots string, hold time.Duration) *site {
+	t.Helper()
+
+	h := &site{}
+	h.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.Write([]byte(robots))
+			return
+		}
+
+		h.mu.Lock()
+		h.inFlight++
+		h.maxInFlight = max(h.maxInFlight, h.inFlight)
+		h.hits = append(h.hits, time.Now())
+		h.mu.Unlock()
+
+		time.Sleep(hold)
+
+		h.mu.Lock()
+		h.inFlight--
+		h.mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html><head><title>page</title></head><body>text</body></html>"))
+	}))
+	t.Cleanup(h.Close)
+
+	return h
+}
+
+// minGap возвращает наименьший интервал между соседними запросами страниц
+func (h *site) minGap() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	gap := time.Duration(1<<63 - 1)
+	for i := 1; i < len(h.hits); i++ {
+		gap = min(gap, h.hits[i].Sub(h.hits[i-1]))
+	}
+
+	return gap
+}
+
+func fetchAll(t *testing.T, s *Scraper, url string, count int) {
+	t.Helper()
+
+	var wg sync.WaitGroup
+
+	for range count {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			page, err := s.GetPage(context.Background(), url, nil)
+			if err != nil || page == nil {
+				t.Errorf("failed to get page [%s]: %v", url, err)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestScraperLimitsRequestsPerHost(t *testing.T) {
+	// Допуск на неточность таймеров
+	const slack = 10 * time.Millisecond
+
+	tests := []struct {
+		name        string
+		robots      string
+		cfg         Config
+		hold        time.Duration
+		requests    int
+		maxInFlight int
+		minGap      time.Duration
+	}{
+		{
+			name:        "in flight limit",
+			cfg:         Config{MaxInFlight: 2},
+			hold:        50 * time.Millisecond,
+			requests:    6,
+			maxInFlight: 2,
+		},
+		{
+			name:        "requests per second",
+			cfg:         Config{RequestsPerSecond: 20, Burst: 1, MaxInFlight: 4},
+			requests:    4,
+			maxInFlight: 4,
+			minGap:      50 * time.Millisecond,
+		},
+		{
+			name:        "crawl delay slower than configured rate",
+			robots:      "User-agent: *\nCrawl-delay: 0.1\n",
+			cfg:         Config{RequestsPerSecond: 100, Burst: 5, MaxInFlight: 4},
+			requests:    3,
+			maxInFlight: 4,
+			minGap:      100 * time.Millisecond,
+		},
+		{
+			name:        "configured rate slower than crawl delay",
+			robots:      "User-agent: *\nCrawl-delay: 0.01\n",
+			cfg:         Config{RequestsPerSecond: 10, Burst: 1, MaxInFlight: 4},
+			requests:    3,
+			maxInFlight: 4,
+			minGap:      100 * time.Millisecond,
+		},
+		{
+			name:        "crawl delay for another agent",
+			robots:      "User-agent: other\nCrawl-delay: 10\n",
+			cfg:         Config{MaxInFlight: 4},
+			requests:    3,
+			maxInFlight: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newSite(t, tt.robots, tt.hold)
+			s := NewScraper(http_scraper.NewGate(userAgent), robots_txt.NewGate(userAgent), tt.cfg)
+
+			start := time.Now()
+			fetchAll(t, s, h.URL+"/page", tt.requests)
+
+			if h.maxInFlight > tt.maxInFlight {
+				t.Fatalf("got %d requests in flight, want at most %d", h.maxInFlight, tt.maxInFlight)
+			}
+
+			if tt.minGap > 0 && h.minGap() < tt.minGap-slack {
+				t.Fatalf("got gap %s between requests, want at least %s", h.minGap(), tt.minGap)
+			}
+
+			if tt.minGap == 0 && time.Since(start) > time.Second {
+				t.Fatalf("requests without rate limit took %s", time.Since(start))
+			}
+		})
+	}
+}
+
+func TestScraperLimitsHostsIndependently(t *testing.T) {
+	slow := newSite(t, "User-agent: *\nCrawl-delay: 1\n", 0)
+	fast := newSite(t, "", 0)
+
+	s := NewScraper(http_scraper.NewGate(userAgent), robots_txt.NewGate(userAgent), Config{MaxInFlight: 1})
+
+	// Первый запрос к медленному хосту проходит сразу, следующий ждет Crawl-delay
+	fetchAll(t, s, slow.URL+"/page", 1)
+
+	var done atomic.Bool
+
+	delayed := make(chan struct{})
+	go func() {
+		defer close(delayed)
+
+		fetchAll(t, s, slow.URL+"/page", 1)
+		done.Store(true)
+	}()
+
+	start := time.Now()
+	fetchAll(t, s, fast.URL+"/page", 3)
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("requests to another host waited %s for crawl delay", elapsed)
+	}
+
+	if done.Load() {
+		t.Fatal("crawl delay of slow host is not applied")
+	}
+
+	<-delayed
+}
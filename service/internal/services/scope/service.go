@@ -0,0 +1,150 @@
+package scope
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/K1flar/crawlers/internal/models/page"
+	"github.com/K1flar/crawlers/internal/models/task"
+	"github.com/samber/lo"
+	"golang.org/x/net/publicsuffix"
+)
+
+// Scope проверяет URL по правилам обхода задачи
+type Scope struct {
+	sameDomain   bool
+	allowHosts   []string
+	denyHosts    []string
+	pathPrefixes []string
+	include      []*regexp.Regexp
+	exclude      []*regexp.Regexp
+	blocked      map[string]struct{}
+}
+
+func New(rules task.ScopeRules) (*Scope, error) {
+	include, err := compile(rules.Include)
+	if err != nil {
+		return nil, fmt.Errorf("invalid include pattern: %w", err)
+	}
+
+	exclude, err := compile(rules.Exclude)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exclude pattern: %w", err)
+	}
+
+	blocked := make(map[string]struct{}, len(rules.BlockedExtensions))
+	for _, ext := range rules.BlockedExtensions {
+		if ext = normalizeExtension(ext); ext != "" {
+			blocked[ext] = struct{}{}
+		}
+	}
+
+	return &Scope{
+		sameDomain:   rules.SameDomain,
+		allowHosts:   normalizeHosts(rules.AllowHosts),
+		denyHosts:    normalizeHosts(rules.DenyHosts),
+		pathPrefixes: lo.Compact(rules.PathPrefixes),
+		include:      include,
+		exclude:      exclude,
+		blocked:      blocked,
+	}, nil
+}
+
+// Check проверяет переход со страницы parent на rawURL. Для стартовых URL parent пустой,
+// и ограничение на домен родителя не применяется
+func (s *Scope) Check(parent, rawURL string) (page.SkipReason, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return page.SkipReasonInvalidURL, false
+	}
+
+	host := strings.ToLower(u.Hostname())
+
+	if s.sameDomain && parent != "" {
+		if p, err := url.Parse(parent); err == nil && domain(p.Hostname()) != domain(host) {
+			return page.SkipReasonOtherDomain, false
+		}
+	}
+
+	if len(s.allowHosts) != 0 && !matchHost(host, s.allowHosts) {
+		return page.SkipReasonHostNotAllowed, false
+	}
+
+	if matchHost(host, s.denyHosts) {
+		return page.SkipReasonHostDenied, false
+	}
+
+	urlPath := lo.Ternary(u.Path == "", "/", u.Path)
+
+	if len(s.pathPrefixes) != 0 && !lo.ContainsBy(s.pathPrefixes, func(prefix string) bool {
+		return strings.HasPrefix(urlPath, prefix)
+	}) {
+		return page.SkipReasonPathNotAllowed, false
+	}
+
+	if _, ok := s.blocked[normalizeExtension(path.Ext(urlPath))]; ok {
+		return page.SkipReasonBlockedExtension, false
+	}
+
+	if len(s.include) != 0 && !matchAny(rawURL, s.include) {
+		return page.SkipReasonNotIncluded, false
+	}
+
+	if matchAny(rawURL, s.exclude) {
+		return page.SkipReasonExcluded, false
+	}
+
+	return "", true
+}
+
+// domain возвращает регистрируемый домен: для news.example.co.uk - example.co.uk
+func domain(host string) string {
+	host = strings.ToLower(host)
+
+	if d, err := publicsuffix.EffectiveTLDPlusOne(host); err == nil {
+		return d
+	}
+
+	return host
+}
+
+func matchHost(host string, hosts []string) bool {
+	return lo.ContainsBy(hosts, func(h string) bool {
+		return host == h || strings.HasSuffix(host, "."+h)
+	})
+}
+
+func matchAny(s string, patterns []*regexp.Regexp) bool {
+	return lo.ContainsBy(patterns, func(re *regexp.Regexp) bool {
+		return re.MatchString(s)
+	})
+}
+
+func compile(patterns []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(patterns))
+
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, re)
+	}
+
+	return res, nil
+}
+
+func normalizeHosts(hosts []string) []string {
+	return lo.Compact(lo.Map(hosts, func(h string, _ int) string {
+		h = strings.ToLower(strings.TrimSpace(h))
+		return strings.TrimPrefix(strings.TrimPrefix(h, "*."), "www.")
+	}))
+}
+
+func normalizeExtension(ext string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), "."))
+}
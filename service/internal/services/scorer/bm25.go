@@ -0,0 +1,47 @@
+package scorer
+
+import (
+	"github.com/K1flar/crawlers/internal/models/document"
+	"github.com/K1flar/crawlers/internal/models/task"
+	"github.com/K1flar/crawlers/internal/services"
+	"github.com/samber/lo"
+)
+
+type bm25 struct {
+	*collection
+	k1, b float64
+}
+
+func newBM25(c *collection, params task.ScorerParams) services.Scorer {
+	return &bm25{
+		collection: c,
+		k1:         lo.FromPtrOr(params.K1, defaultK1),
+		b:          lo.FromPtrOr(params.B, defaultB),
+	}
+}
+
+func (s *bm25) Score(url string) (float64, bool) {
+	doc, ok := s.docs[url]
+	if !ok {
+		return 0, false
+	}
+
+	return s.score(doc), true
+}
+
+func (s *bm25) score(doc document.Document) float64 {
+	norm := lengthNorm(doc.Size, s.avgSize(), s.b)
+
+	score := float64(0)
+
+	for _, term := range s.terms {
+		tf := float64(doc.TF[term])
+		if tf == 0 {
+			continue
+		}
+
+		score += s.idf(term) * tf * (s.k1 + 1) / (tf + s.k1*norm)
+	}
+
+	return score
+}
@@ -0,0 +1,48 @@
+package scorer
+
+import (
+	"github.com/K1flar/crawlers/internal/models/task"
+	"github.com/K1flar/crawlers/internal/services"
+	"github.com/samber/lo"
+)
+
+// bm25f учитывает заголовок и текст страницы как отдельные поля со своими весами
+type bm25f struct {
+	*collection
+	k1, b                   float64
+	titleWeight, bodyWeight float64
+}
+
+func newBM25F(c *collection, params task.ScorerParams) services.Scorer {
+	return &bm25f{
+		collection:  c,
+		k1:          lo.FromPtrOr(params.K1, defaultK1),
+		b:           lo.FromPtrOr(params.B, defaultB),
+		titleWeight: lo.FromPtrOr(params.TitleWeight, defaultTitleWeight),
+		bodyWeight:  lo.FromPtrOr(params.BodyWeight, defaultBodyWeight),
+	}
+}
+
+func (s *bm25f) Score(url string) (float64, bool) {
+	doc, ok := s.docs[url]
+	if !ok {
+		return 0, false
+	}
+
+	titleNorm := lengthNorm(doc.TitleSize, s.avgTitleSize(), s.b)
+	bodyNorm := lengthNorm(doc.Size, s.avgSize(), s.b)
+
+	score := float64(0)
+
+	for _, term := range s.terms {
+		tf := s.titleWeight*float64(doc.TitleTF[term])/titleNorm +
+			s.bodyWeight*float64(doc.TF[term])/bodyNorm
+		if tf == 0 {
+			continue
+		}
+
+		score += s.idf(term) * tf * (s.k1 + 1) / (tf + s.k1)
+	}
+
+	return score, true
+}
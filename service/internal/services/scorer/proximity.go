@@ -0,0 +1,66 @@
+package scorer
+
+import (
+	"math"
+
+	"github.com/K1flar/crawlers/internal/models/document"
+	"github.com/K1flar/crawlers/internal/models/task"
+	"github.com/K1flar/crawlers/internal/services"
+)
+
+// proximity добавляет к BM25 бонус за близкое расположение терминов запроса в тексте
+type proximity struct {
+	*bm25
+}
+
+func newProximity(c *collection, params task.ScorerParams) services.Scorer {
+	return &proximity{newBM25(c, params).(*bm25)}
+}
+
+func (s *proximity) Score(url string) (float64, bool) {
+	doc, ok := s.docs[url]
+	if !ok {
+		return 0, false
+	}
+
+	score := s.score(doc)
+
+	for i := range s.terms {
+		for j := i + 1; j < len(s.terms); j++ {
+			d := minDistance(doc, s.terms[i], s.terms[j])
+			if d == 0 {
+				continue
+			}
+
+			score += math.Min(s.idf(s.terms[i]), s.idf(s.terms[j])) / float64(d*d)
+		}
+	}
+
+	return score, true
+}
+
+// minDistance возвращает наименьшее расстояние в словах между двумя терминами, 0 - термины не встретились
+func minDistance(doc document.Document, a, b string) int {
+	pa, pb := doc.Positions[a], doc.Positions[b]
+
+	best := 0
+
+	for i, j := 0, 0; i < len(pa) && j < len(pb); {
+		d := pa[i] - pb[j]
+		if d < 0 {
+			d = -d
+		}
+
+		if best == 0 || d < best {
+			best = d
+		}
+
+		if pa[i] < pb[j] {
+			i++
+		} else {
+			j++
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,187 @@
+package scorer
+
+import (
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/K1flar/crawlers/internal/business_errors"
+	"github.com/K1flar/crawlers/internal/models/document"
+	page_models "github.com/K1flar/crawlers/internal/models/page"
+	"github.com/K1flar/crawlers/internal/models/task"
+	"github.com/K1flar/crawlers/internal/services"
+	"github.com/K1flar/crawlers/internal/services/analyzer"
+	"github.com/samber/lo"
+)
+
+type Name string
+
+const (
+	BM25      Name = "bm25"
+	TFIDF     Name = "tfidf"
+	BM25F     Name = "bm25f"
+	Proximity Name = "proximity"
+
+	Default = BM25
+)
+
+const (
+	// Гиперпараметры для алгоритмов семейства BM25
+	defaultK1 = 1.2
+	defaultB  = 0.75
+
+	// Веса полей для BM25F
+	defaultTitleWeight = 2.0
+	defaultBodyWeight  = 1.0
+)
+
+type constructor func(c *collection, params task.ScorerParams) services.Scorer
+
+var registry = map[Name]constructor{
+	BM25:      newBM25,
+	TFIDF:     newTFIDF,
+	BM25F:     newBM25F,
+	Proximity: newProximity,
+}
+
+func Exists(name string) bool {
+	_, ok := registry[Name(name)]
+	return ok
+}
+
+// ValidateParams проверяет гиперпараметры, незаданные параметры берутся по умолчанию
+func ValidateParams(params task.ScorerParams) error {
+	if lo.FromPtrOr(params.K1, defaultK1) < 0 {
+		return errors.New("k1 must be non-negative")
+	}
+
+	if b := lo.FromPtrOr(params.B, defaultB); b < 0 || b > 1 {
+		return errors.New("b must be between 0 and 1")
+	}
+
+	titleWeight := lo.FromPtrOr(params.TitleWeight, defaultTitleWeight)
+	bodyWeight := lo.FromPtrOr(params.BodyWeight, defaultBodyWeight)
+
+	if titleWeight < 0 || bodyWeight < 0 {
+		return errors.New("field weights must be non-negative")
+	}
+
+	if titleWeight == 0 && bodyWeight == 0 {
+		return errors.New("at least one field weight must be positive")
+	}
+
+	return nil
+}
+
+// New создает функцию ранжирования по названию, пустое название - функция по умолчанию.
+// Запрос и страницы приводятся к терминам одним анализатором
+func New(name string, query string, params task.ScorerParams, analyzer *analyzer.Analyzer) (services.Scorer, error) {
+	if name == "" {
+		name = string(Default)
+	}
+
+	constructor, ok := registry[Name(name)]
+	if !ok {
+		return nil, business_errors.UnknownScorer
+	}
+
+	return constructor(newCollection(query, analyzer), params), nil
+}
+
+// collection - статистика по коллекции документов, общая для всех функций ранжирования
+type collection struct {
+	analyzer  *analyzer.Analyzer
+	terms     []string                     // термины запроса
+	docs      map[string]document.Document // коллекция документов по URL
+	df        map[string]int               // количество документов, содержащих определенный термин
+	totalSize int64                        // общий размер слов в коллекции
+	titleSize int64                        // общий размер слов в заголовках
+}
+
+func newCollection(query string, analyzer *analyzer.Analyzer) *collection {
+	return &collection{
+		analyzer: analyzer,
+		terms:    lo.Uniq(analyzer.Analyze(query)),
+		docs:     map[string]document.Document{},
+		df:       map[string]int{},
+	}
+}
+
+func (c *collection) AddPage(url string, page page_models.Page) {
+	if _, ok := c.docs[url]; ok {
+		return
+	}
+
+	words := c.analyzer.Analyze(page.Content)
+	// Подзаголовки страницы относятся к полю заголовка наравне с title
+	headings := lo.Map(page.Headings, func(h page_models.Heading, _ int) string {
+		return h.Text
+	})
+	title := c.analyzer.Analyze(strings.Join(append([]string{page.Title}, headings...), "\n"))
+
+	isTerm := lo.SliceToMap(c.terms, func(term string) (string, struct{}) {
+		return term, struct{}{}
+	})
+
+	tf := make(map[string]int64)
+	positions := make(map[string][]int, len(c.terms))
+
+	for i, word := range words {
+		tf[word]++
+
+		if _, ok := isTerm[word]; ok {
+			positions[word] = append(positions[word], i)
+		}
+	}
+
+	titleTF := make(map[string]int64)
+	for _, word := range title {
+		titleTF[word]++
+	}
+
+	c.docs[url] = document.Document{
+		URL:       url,
+		Size:      int64(len(words)),
+		TF:        tf,
+		TitleSize: int64(len(title)),
+		TitleTF:   titleTF,
+		Positions: positions,
+	}
+
+	c.totalSize += int64(len(words))
+	c.titleSize += int64(len(title))
+
+	for term := range tf {
+		c.df[term]++
+	}
+}
+
+func (c *collection) avgSize() float64 {
+	return float64(c.totalSize) / float64(max(len(c.docs), 1))
+}
+
+func (c *collection) avgTitleSize() float64 {
+	return float64(c.titleSize) / float64(max(len(c.docs), 1))
+}
+
+// idf https://habr.com/ru/articles/840268/
+func (c *collection) idf(term string) float64 {
+	totalDocs := float64(len(c.docs))
+	df := float64(c.df[term])
+
+	return math.Log((totalDocs-df+0.5)/(df+0.5) + 1.0)
+}
+
+// lengthNorm - нормализация частоты термина по длине поля
+func lengthNorm(size int64, avgSize, b float64) float64 {
+	if avgSize == 0 {
+		return 1
+	}
+
+	// Пустое поле при b=1 дает нулевую норму, терминов в нем все равно нет
+	if norm := 1 - b + b*float64(size)/avgSize; norm > 0 {
+		return norm
+	}
+
+	return 1
+}
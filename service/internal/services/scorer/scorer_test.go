@@ -0,0 +1,209 @@
+package scorer
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	page_models "github.com/K1flar/crawlers/internal/models/page"
+	"github.com/K1flar/crawlers/internal/models/task"
+	"github.com/K1flar/crawlers/internal/services"
+	"github.com/K1flar/crawlers/internal/services/analyzer"
+	"github.com/samber/lo"
+)
+
+func newScorer(t *testing.T, name Name, query string, params task.ScorerParams, pages map[string]page_models.Page) services.Scorer {
+	t.Helper()
+
+	s, err := New(string(name), query, params, analyzer.New(analyzer.English))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for url, page := range pages {
+		s.AddPage(url, page)
+	}
+
+	return s
+}
+
+func score(t *testing.T, s services.Scorer, url string) float64 {
+	t.Helper()
+
+	weight, ok := s.Score(url)
+	if !ok {
+		t.Fatalf("no weight for %s", url)
+	}
+
+	if math.IsNaN(weight) || math.IsInf(weight, 0) {
+		t.Fatalf("weight of %s is %v", url, weight)
+	}
+
+	return weight
+}
+
+func TestScorersRankByQueryTerms(t *testing.T) {
+	pages := map[string]page_models.Page{
+		"both":  {Title: "Crawler", Content: "web crawler written in golang, the crawler fetches pages"},
+		"one":   {Title: "Notes", Content: "notes about a crawler and some unrelated words here"},
+		"none":  {Title: "Cooking", Content: "recipes for soup, bread and other tasty things"},
+		"empty": {},
+	}
+
+	for name := range registry {
+		t.Run(string(name), func(t *testing.T) {
+			s := newScorer(t, name, "golang crawler", task.ScorerParams{}, pages)
+
+			both, one, none, empty := score(t, s, "both"), score(t, s, "one"), score(t, s, "none"), score(t, s, "empty")
+
+			if !(both > one && one > none) {
+				t.Fatalf("wrong ranking: both %v, one %v, none %v", both, one, none)
+			}
+
+			if none != 0 || empty != 0 {
+				t.Fatalf("pages without query terms scored: none %v, empty %v", none, empty)
+			}
+
+			if _, ok := s.Score("unknown"); ok {
+				t.Fatal("weight for unknown page")
+			}
+		})
+	}
+}
+
+func TestProximityPrefersCloseTerms(t *testing.T) {
+	filler := strings.Repeat("filler ", 20)
+
+	s := newScorer(t, Proximity, "golang crawler", task.ScorerParams{}, map[string]page_models.Page{
+		"close": {Content: "golang crawler " + filler},
+		"far":   {Content: "golang " + filler + "crawler"},
+	})
+
+	if close, far := score(t, s, "close"), score(t, s, "far"); close <= far {
+		t.Fatalf("close terms %v, far terms %v", close, far)
+	}
+}
+
+func TestBM25FWeightsTitle(t *testing.T) {
+	pages := map[string]page_models.Page{
+		"title": {Title: "golang", Content: "about programming languages"},
+		"body":  {Title: "programming", Content: "about golang languages"},
+	}
+
+	s := newScorer(t, BM25F, "golang", task.ScorerParams{}, pages)
+	if title, body := score(t, s, "title"), score(t, s, "body"); title <= body {
+		t.Fatalf("title match %v, body match %v", title, body)
+	}
+
+	s = newScorer(t, BM25F, "golang", task.ScorerParams{TitleWeight: lo.ToPtr(0.0)}, pages)
+	if title := score(t, s, "title"); title != 0 {
+		t.Fatalf("title match with zero title weight %v", title)
+	}
+}
+
+func TestScorersDegenerateInput(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		params task.ScorerParams
+		pages  map[string]page_models.Page
+	}{
+		{
+			name:  "empty query",
+			query: "",
+			pages: map[string]page_models.Page{"a": {Title: "golang", Content: "golang crawler"}},
+		},
+		{
+			name:  "query of stop words",
+			query: "the and of",
+			pages: map[string]page_models.Page{"a": {Title: "golang", Content: "golang crawler"}},
+		},
+		{
+			name:  "zero average length",
+			query: "golang",
+			pages: map[string]page_models.Page{"a": {}, "b": {}},
+		},
+		{
+			name:   "empty title and body with b=1",
+			query:  "golang",
+			params: task.ScorerParams{B: lo.ToPtr(1.0)},
+			pages: map[string]page_models.Page{
+				"a": {Content: "golang crawler"},
+				"b": {Title: "golang"},
+				"c": {},
+			},
+		},
+		{
+			name:   "zero k1",
+			query:  "golang crawler",
+			params: task.ScorerParams{K1: lo.ToPtr(0.0)},
+			pages: map[string]page_models.Page{
+				"a": {Content: "golang"},
+				"b": {Content: "crawler"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		for name := range registry {
+			t.Run(tt.name+"/"+string(name), func(t *testing.T) {
+				s := newScorer(t, name, tt.query, tt.params, tt.pages)
+
+				for url := range tt.pages {
+					if weight := score(t, s, url); weight < 0 {
+						t.Fatalf("negative weight of %s: %v", url, weight)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestLengthNorm(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int64
+		avgSize float64
+		b       float64
+		want    float64
+	}{
+		{"average length", 10, 10, 0.75, 1},
+		{"no length normalization", 30, 10, 0, 1},
+		{"full length normalization", 30, 10, 1, 3},
+		{"zero average length", 0, 0, 0.75, 1},
+		{"empty field with b=1", 0, 10, 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lengthNorm(tt.size, tt.avgSize, tt.b); got != tt.want {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  task.ScorerParams
+		wantErr bool
+	}{
+		{"defaults", task.ScorerParams{}, false},
+		{"bounds", task.ScorerParams{K1: lo.ToPtr(0.0), B: lo.ToPtr(1.0)}, false},
+		{"only body", task.ScorerParams{TitleWeight: lo.ToPtr(0.0)}, false},
+		{"negative k1", task.ScorerParams{K1: lo.ToPtr(-0.1)}, true},
+		{"negative b", task.ScorerParams{B: lo.ToPtr(-0.1)}, true},
+		{"b above one", task.ScorerParams{B: lo.ToPtr(1.1)}, true},
+		{"negative field weight", task.ScorerParams{BodyWeight: lo.ToPtr(-1.0)}, true},
+		{"zero field weights", task.ScorerParams{TitleWeight: lo.ToPtr(0.0), BodyWeight: lo.ToPtr(0.0)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateParams(tt.params); (err != nil) != tt.wantErr {
+				t.Fatalf("got %v, want error %t", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package scorer
+
+import (
+	"math"
+
+	"github.com/K1flar/crawlers/internal/models/task"
+	"github.com/K1flar/crawlers/internal/services"
+)
+
+// tfidf - косинусная близость векторов запроса и документа в пространстве TF-IDF
+type tfidf struct {
+	*collection
+}
+
+func newTFIDF(c *collection, _ task.ScorerParams) services.Scorer {
+	return &tfidf{c}
+}
+
+func (s *tfidf) Score(url string) (float64, bool) {
+	doc, ok := s.docs[url]
+	if !ok {
+		return 0, false
+	}
+
+	var dot, queryNorm, docNorm float64
+
+	for _, term := range s.terms {
+		idf := s.weightIDF(term)
+		queryNorm += idf * idf
+
+		if tf := doc.TF[term]; tf > 0 {
+			dot += idf * s.weightTF(tf) * idf
+		}
+	}
+
+	for term, tf := range doc.TF {
+		w := s.weightTF(tf) * s.weightIDF(term)
+		docNorm += w * w
+	}
+
+	if queryNorm == 0 || docNorm == 0 {
+		return 0, true
+	}
+
+	return dot / (math.Sqrt(queryNorm) * math.Sqrt(docNorm)), true
+}
+
+func (s *tfidf) weightTF(tf int64) float64 {
+	return 1 + math.Log(float64(tf))
+}
+
+// weightIDF сглажен, чтобы термины из всех документов не обнуляли вектор
+func (s *tfidf) weightIDF(term string) float64 {
+	return math.Log(1 + float64(len(s.docs))/float64(s.df[term]+1))
+}
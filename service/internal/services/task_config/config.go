@@ -0,0 +1,225 @@
+package task_config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/K1flar/crawlers/internal/models/task"
+	"github.com/K1flar/crawlers/internal/services/analyzer"
+	"github.com/K1flar/crawlers/internal/services/scope"
+	"github.com/K1flar/crawlers/internal/services/scorer"
+	"github.com/robfig/cron/v3"
+	"github.com/samber/lo"
+)
+
+// Ограничения параметров обхода на стороне сервера
+const (
+	MaxDepthLevel            = 5
+	MaxSources               = 500
+	MaxNeighboursForSource   = 100
+	MaxHostRequestsPerSecond = 10
+	MaxHostBurst             = 20
+	MaxHostMaxInFlight       = 10
+	MaxDuplicateDistance     = 64 // Отпечатки SimHash 64-битные
+	MinScheduleInterval      = time.Hour
+	MaxScheduleInterval      = time.Hour * 24 * 366
+	DefaultScheduleInterval  = time.Hour * 24
+)
+
+const (
+	defaultDepthLevel             = 3
+	defaultMaxSources             = 20
+	defaultMaxNeighboursForSource = 20
+	defaultHostRequestsPerSecond  = 1
+	defaultHostBurst              = 1
+	defaultHostMaxInFlight        = 2
+	defaultDuplicateDistance      = 3
+
+	// Число ближайших запусков cron-выражения, по которым проверяется частота
+	checkedScheduleRuns = 16
+)
+
+// По умолчанию не загружаются медиафайлы, архивы и исполняемые файлы
+var defaultBlockedExtensions = []string{
+	"jpg", "jpeg", "png", "gif", "webp", "svg", "ico", "bmp",
+	"mp3", "mp4", "avi", "mov", "webm",
+	"zip", "rar", "7z", "gz", "tar",
+	"exe", "dmg", "iso", "apk",
+	"css", "js", "woff", "woff2", "ttf",
+}
+
+// Error - параметры обхода нарушают ограничения
+type Error struct {
+	msg string
+}
+
+func (e *Error) Error() string {
+	return e.msg
+}
+
+func errorf(format string, args ...any) error {
+	return &Error{fmt.Sprintf(format, args...)}
+}
+
+func Default() task.Config {
+	return task.Config{
+		DepthLevel:             defaultDepthLevel,
+		MaxSources:             defaultMaxSources,
+		MaxNeighboursForSource: defaultMaxNeighboursForSource,
+		HostRequestsPerSecond:  defaultHostRequestsPerSecond,
+		HostBurst:              defaultHostBurst,
+		HostMaxInFlight:        defaultHostMaxInFlight,
+		Scorer:                 string(scorer.Default),
+		Language:               string(analyzer.Default),
+		DuplicateDistance:      defaultDuplicateDistance,
+		ScopeRules: task.ScopeRules{
+			BlockedExtensions: defaultBlockedExtensions,
+		},
+	}
+}
+
+// Patch - изменение параметров обхода, nil поля остаются прежними
+type Patch struct {
+	DepthLevel             *int
+	MinWeight              *float64
+	MaxSources             *int64
+	MaxNeighboursForSource *int64
+	HostRequestsPerSecond  *float64
+	HostBurst              *int64
+	HostMaxInFlight        *int64
+	Scorer                 *string
+	ScorerParams           *task.ScorerParams
+	Language               *string
+	DuplicateDistance      *int
+	ScopeRules             *task.ScopeRules // заменяет правила целиком
+	Schedule               *string
+}
+
+func (p Patch) Apply(cfg task.Config) task.Config {
+	cfg.DepthLevel = lo.FromPtrOr(p.DepthLevel, cfg.DepthLevel)
+	cfg.MinWeight = lo.FromPtrOr(p.MinWeight, cfg.MinWeight)
+	cfg.MaxSources = lo.FromPtrOr(p.MaxSources, cfg.MaxSources)
+	cfg.MaxNeighboursForSource = lo.FromPtrOr(p.MaxNeighboursForSource, cfg.MaxNeighboursForSource)
+	cfg.HostRequestsPerSecond = lo.FromPtrOr(p.HostRequestsPerSecond, cfg.HostRequestsPerSecond)
+	cfg.HostBurst = lo.FromPtrOr(p.HostBurst, cfg.HostBurst)
+	cfg.HostMaxInFlight = lo.FromPtrOr(p.HostMaxInFlight, cfg.HostMaxInFlight)
+	cfg.Scorer = lo.FromPtrOr(p.Scorer, cfg.Scorer)
+	cfg.ScorerParams = lo.FromPtrOr(p.ScorerParams, cfg.ScorerParams)
+	cfg.Language = lo.FromPtrOr(p.Language, cfg.Language)
+	cfg.DuplicateDistance = lo.FromPtrOr(p.DuplicateDistance, cfg.DuplicateDistance)
+	cfg.ScopeRules = lo.FromPtrOr(p.ScopeRules, cfg.ScopeRules)
+	cfg.Schedule = lo.FromPtrOr(p.Schedule, cfg.Schedule)
+
+	return cfg
+}
+
+// Validate проверяет параметры обхода, нарушение ограничений возвращается как *Error
+func Validate(cfg task.Config) error {
+	if cfg.DepthLevel < 1 || cfg.DepthLevel > MaxDepthLevel {
+		return errorf("depth level must be between 1 and %d", MaxDepthLevel)
+	}
+
+	if cfg.MinWeight < 0 {
+		return errorf("negative min weight")
+	}
+
+	if cfg.MaxSources < 1 || cfg.MaxSources > MaxSources {
+		return errorf("max sources must be between 1 and %d", MaxSources)
+	}
+
+	if cfg.MaxNeighboursForSource < 1 || cfg.MaxNeighboursForSource > MaxNeighboursForSource {
+		return errorf("max neighbours for source must be between 1 and %d", MaxNeighboursForSource)
+	}
+
+	if cfg.HostRequestsPerSecond <= 0 || cfg.HostRequestsPerSecond > MaxHostRequestsPerSecond {
+		return errorf("host requests per second must be in (0, %d]", MaxHostRequestsPerSecond)
+	}
+
+	if cfg.HostBurst < 1 || cfg.HostBurst > MaxHostBurst {
+		return errorf("host burst must be between 1 and %d", MaxHostBurst)
+	}
+
+	if cfg.HostMaxInFlight < 1 || cfg.HostMaxInFlight > MaxHostMaxInFlight {
+		return errorf("host max in flight must be between 1 and %d", MaxHostMaxInFlight)
+	}
+
+	if !scorer.Exists(cfg.Scorer) {
+		return errorf("unknown scorer")
+	}
+
+	if err := scorer.ValidateParams(cfg.ScorerParams); err != nil {
+		return errorf("invalid scorer params: %s", err)
+	}
+
+	if lo.FromPtr(cfg.ScorerParams.PageRankWeight) < 0 || lo.FromPtr(cfg.ScorerParams.AuthorityWeight) < 0 {
+		return errorf("negative link graph weight")
+	}
+
+	if _, err := analyzer.ParseLanguage(cfg.Language); err != nil {
+		return errorf("unknown language")
+	}
+
+	if cfg.DuplicateDistance < 0 || cfg.DuplicateDistance > MaxDuplicateDistance {
+		return errorf("duplicate distance must be between 0 and %d", MaxDuplicateDistance)
+	}
+
+	if _, err := scope.New(cfg.ScopeRules); err != nil {
+		return errorf("invalid scope rules")
+	}
+
+	if _, err := ParseSchedule(cfg.Schedule); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ParseSchedule разбирает расписание задачи: интервал (12h) или cron-выражение (0 6 * * 1, @monthly).
+// Пустое расписание - запуск раз в DefaultScheduleInterval
+func ParseSchedule(s string) (cron.Schedule, error) {
+	if s == "" {
+		return cron.Every(DefaultScheduleInterval), nil
+	}
+
+	if interval, err := time.ParseDuration(s); err == nil {
+		if interval < MinScheduleInterval || interval > MaxScheduleInterval {
+			return nil, errorf("schedule interval must be between %s and %s", MinScheduleInterval, MaxScheduleInterval)
+		}
+
+		return cron.Every(interval), nil
+	}
+
+	schedule, err := cron.ParseStandard(s)
+	if err != nil {
+		return nil, errorf("schedule must be an interval like 12h or a cron expression")
+	}
+
+	prev := schedule.Next(time.Now())
+	for range checkedScheduleRuns {
+		next := schedule.Next(prev)
+
+		// Пустое время - выражение не срабатывает в ближайшие годы
+		if prev.IsZero() || next.IsZero() || next.Sub(prev) > MaxScheduleInterval {
+			return nil, errorf("schedule runs less often than once in %s", MaxScheduleInterval)
+		}
+
+		if next.Sub(prev) < MinScheduleInterval {
+			return nil, errorf("schedule runs more often than once in %s", MinScheduleInterval)
+		}
+
+		prev = next
+	}
+
+	return schedule, nil
+}
+
+// NextRun возвращает время запуска по расписанию после after.
+// Некорректное расписание заменяется расписанием по умолчанию
+func NextRun(schedule string, after time.Time) time.Time {
+	s, err := ParseSchedule(schedule)
+	if err != nil {
+		s = cron.Every(DefaultScheduleInterval)
+	}
+
+	return s.Next(after)
+}
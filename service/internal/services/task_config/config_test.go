@@ -0,0 +1,92 @@
+package task_config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/K1flar/crawlers/internal/models/task"
+	"github.com/samber/lo"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		patch   func(cfg *task.Config)
+		wantErr bool
+	}{
+		{"defaults", func(cfg *task.Config) {}, false},
+
+		{"min depth level", func(cfg *task.Config) { cfg.DepthLevel = 1 }, false},
+		{"zero depth level", func(cfg *task.Config) { cfg.DepthLevel = 0 }, true},
+		{"too deep", func(cfg *task.Config) { cfg.DepthLevel = MaxDepthLevel + 1 }, true},
+		{"negative min weight", func(cfg *task.Config) { cfg.MinWeight = -0.1 }, true},
+		{"max sources", func(cfg *task.Config) { cfg.MaxSources = MaxSources }, false},
+		{"too many sources", func(cfg *task.Config) { cfg.MaxSources = MaxSources + 1 }, true},
+		{"zero neighbours", func(cfg *task.Config) { cfg.MaxNeighboursForSource = 0 }, true},
+		{"too many neighbours", func(cfg *task.Config) { cfg.MaxNeighboursForSource = MaxNeighboursForSource + 1 }, true},
+
+		{"max host rate", func(cfg *task.Config) { cfg.HostRequestsPerSecond = MaxHostRequestsPerSecond }, false},
+		{"fractional host rate", func(cfg *task.Config) { cfg.HostRequestsPerSecond = 0.5 }, false},
+		{"zero host rate", func(cfg *task.Config) { cfg.HostRequestsPerSecond = 0 }, true},
+		{"too high host rate", func(cfg *task.Config) { cfg.HostRequestsPerSecond = MaxHostRequestsPerSecond + 1 }, true},
+		{"zero host burst", func(cfg *task.Config) { cfg.HostBurst = 0 }, true},
+		{"too big host burst", func(cfg *task.Config) { cfg.HostBurst = MaxHostBurst + 1 }, true},
+		{"zero host in flight", func(cfg *task.Config) { cfg.HostMaxInFlight = 0 }, true},
+		{"too many host in flight", func(cfg *task.Config) { cfg.HostMaxInFlight = MaxHostMaxInFlight + 1 }, true},
+
+		{"known scorer", func(cfg *task.Config) { cfg.Scorer = "bm25f" }, false},
+		{"unknown scorer", func(cfg *task.Config) { cfg.Scorer = "pagerank" }, true},
+		{"scorer params", func(cfg *task.Config) { cfg.ScorerParams = task.ScorerParams{K1: lo.ToPtr(2.0), B: lo.ToPtr(0.5)} }, false},
+		{"b above one", func(cfg *task.Config) { cfg.ScorerParams.B = lo.ToPtr(1.5) }, true},
+		{"negative k1", func(cfg *task.Config) { cfg.ScorerParams.K1 = lo.ToPtr(-1.0) }, true},
+		{"zero field weights", func(cfg *task.Config) {
+			cfg.ScorerParams.TitleWeight, cfg.ScorerParams.BodyWeight = lo.ToPtr(0.0), lo.ToPtr(0.0)
+		}, true},
+		{"negative page rank weight", func(cfg *task.Config) { cfg.ScorerParams.PageRankWeight = lo.ToPtr(-1.0) }, true},
+		{"negative authority weight", func(cfg *task.Config) { cfg.ScorerParams.AuthorityWeight = lo.ToPtr(-1.0) }, true},
+
+		{"english", func(cfg *task.Config) { cfg.Language = "english" }, false},
+		{"empty language is default", func(cfg *task.Config) { cfg.Language = "" }, false},
+		{"unknown language", func(cfg *task.Config) { cfg.Language = "klingon" }, true},
+
+		{"exact duplicates only", func(cfg *task.Config) { cfg.DuplicateDistance = 0 }, false},
+		{"max duplicate distance", func(cfg *task.Config) { cfg.DuplicateDistance = MaxDuplicateDistance }, false},
+		{"negative duplicate distance", func(cfg *task.Config) { cfg.DuplicateDistance = -1 }, true},
+		{"too big duplicate distance", func(cfg *task.Config) { cfg.DuplicateDistance = MaxDuplicateDistance + 1 }, true},
+
+		{"scope rules", func(cfg *task.Config) {
+			cfg.ScopeRules = task.ScopeRules{SameDomain: true, Include: []string{`^/blog/`}, Exclude: []string{`\?page=`}}
+		}, false},
+		{"invalid include pattern", func(cfg *task.Config) { cfg.ScopeRules.Include = []string{"(["} }, true},
+		{"invalid exclude pattern", func(cfg *task.Config) { cfg.ScopeRules.Exclude = []string{"(["} }, true},
+
+		{"interval schedule", func(cfg *task.Config) { cfg.Schedule = "12h" }, false},
+		{"cron schedule", func(cfg *task.Config) { cfg.Schedule = "0 6 * * 1" }, false},
+		{"descriptor schedule", func(cfg *task.Config) { cfg.Schedule = "@monthly" }, false},
+		{"too short interval", func(cfg *task.Config) { cfg.Schedule = "30m" }, true},
+		{"too long interval", func(cfg *task.Config) { cfg.Schedule = "9000h" }, true},
+		{"too frequent cron", func(cfg *task.Config) { cfg.Schedule = "*/5 * * * *" }, true},
+		{"never firing cron", func(cfg *task.Config) { cfg.Schedule = "0 0 30 2 *" }, true},
+		{"malformed schedule", func(cfg *task.Config) { cfg.Schedule = "every day" }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.patch(&cfg)
+
+			err := Validate(cfg)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var configError *Error
+			if !errors.As(err, &configError) {
+				t.Fatalf("expected *Error, got %v", err)
+			}
+		})
+	}
+}
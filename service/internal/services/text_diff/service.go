@@ -0,0 +1,208 @@
+package text_diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// Количество неизмененных строк вокруг изменений
+	contextLines = 3
+
+	// Ограничение на число правок, после которого тексты считаются полностью разными
+	maxEditDistance = 1000
+)
+
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+type op struct {
+	kind opKind
+	line string
+	// Номера строк в исходном и новом тексте (с нуля)
+	a, b int
+}
+
+// Unified возвращает построчную разницу между текстами в формате unified diff.
+// Строки сравниваются без начальных и конечных пробелов, пустые строки игнорируются
+func Unified(from, to string) string {
+	ops := diff(lines(from), lines(to))
+
+	var sb strings.Builder
+
+	for _, h := range hunks(ops) {
+		first := ops[h.start]
+
+		var aCount, bCount int
+		for _, op := range ops[h.start:h.end] {
+			if op.kind != opInsert {
+				aCount++
+			}
+			if op.kind != opDelete {
+				bCount++
+			}
+		}
+
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", lineRange(first.a, aCount), lineRange(first.b, bCount))
+
+		for _, op := range ops[h.start:h.end] {
+			sb.WriteByte(byte(op.kind))
+			sb.WriteString(op.line)
+			sb.WriteByte('\n')
+		}
+	}
+
+	return sb.String()
+}
+
+func lines(text string) []string {
+	out := make([]string, 0)
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		out = append(out, line)
+	}
+
+	return out
+}
+
+func lineRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+type hunk struct {
+	start, end int
+}
+
+// hunks группирует изменения вместе с окружающим их контекстом
+func hunks(ops []op) []hunk {
+	out := make([]hunk, 0)
+
+	for i, op := range ops {
+		if op.kind == opEqual {
+			continue
+		}
+
+		start, end := max(0, i-contextLines), min(len(ops), i+contextLines+1)
+
+		if len(out) > 0 && out[len(out)-1].end >= start {
+			out[len(out)-1].end = end
+			continue
+		}
+
+		out = append(out, hunk{start, end})
+	}
+
+	return out
+}
+
+// diff строит кратчайший сценарий правок алгоритмом Майерса.
+// На шаге d след хранит только диагонали [-d-1, d+1], которые читает обратный проход:
+// при maxEditDistance это не больше миллиона чисел вместо копии всего v на каждом шаге
+func diff(a, b []string) []op {
+	n, m := len(a), len(b)
+	maxD := min(n+m, maxEditDistance)
+	offset := maxD + 1
+
+	v := make([]int, 2*offset+1)
+	trace := make([][]int, 0)
+
+	for d := 0; d <= maxD; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(a, b, trace)
+			}
+		}
+	}
+
+	return replaceAll(a, b)
+}
+
+func backtrack(a, b []string, trace [][]int) []op {
+	x, y := len(a), len(b)
+	out := make([]op, 0, x+y)
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		// Диагональ k шага d хранится в trace[d][k+offset]
+		v, offset := trace[d], d+1
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			out = append(out, op{opEqual, a[x], x, y})
+		}
+
+		if d == 0 {
+			break
+		}
+
+		if x == prevX {
+			y--
+			out = append(out, op{opInsert, b[y], x, y})
+		} else {
+			x--
+			out = append(out, op{opDelete, a[x], x, y})
+		}
+	}
+
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+
+	return out
+}
+
+func replaceAll(a, b []string) []op {
+	out := make([]op, 0, len(a)+len(b))
+
+	for i, line := range a {
+		out = append(out, op{opDelete, line, i, 0})
+	}
+
+	for i, line := range b {
+		out = append(out, op{opInsert, line, len(a), i})
+	}
+
+	return out
+}
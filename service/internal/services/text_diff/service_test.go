@@ -0,0 +1,86 @@
+package text_diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func numbered(prefix string, n int) string {
+	lines := make([]string, 0, n)
+	for i := range n {
+		lines = append(lines, fmt.Sprintf("%s%d", prefix, i))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		want     string
+	}{
+		{"identical", "a\nb\nc", "a\nb\nc", ""},
+		{"whitespace and empty lines are ignored", "  a \n\n b", "a\nb", ""},
+		{"both empty", "", "", ""},
+		{"insert into empty", "", "a\nb", "@@ -0,0 +1,2 @@\n+a\n+b\n"},
+		{"delete everything", "a\nb", "", "@@ -1,2 +0,0 @@\n-a\n-b\n"},
+		{"pure insert", "a\nb\nc", "a\nx\nb\nc", "@@ -1,3 +1,4 @@\n a\n+x\n b\n c\n"},
+		{"pure delete", "a\nb\nc\nd", "a\nc\nd", "@@ -1,4 +1,3 @@\n a\n-b\n c\n d\n"},
+		{
+			"close changes share a hunk",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10",
+			"1\nX\n3\n4\n5\n6\n7\nY\n9\n10",
+			"@@ -1,10 +1,10 @@\n 1\n-2\n+X\n 3\n 4\n 5\n 6\n 7\n-8\n+Y\n 9\n 10\n",
+		},
+		{
+			"distant changes get own hunks",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12",
+			"X\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\nY",
+			"@@ -1,4 +1,4 @@\n-1\n+X\n 2\n 3\n 4\n@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+Y\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified(tt.from, tt.to); got != tt.want {
+				t.Fatalf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffIsShortest(t *testing.T) {
+	a := lines(numbered("line", 200))
+	b := append(append([]string{"new"}, a[:100]...), a[101:]...)
+
+	ops := diff(a, b)
+
+	edits := 0
+	for _, op := range ops {
+		if op.kind != opEqual {
+			edits++
+		}
+	}
+
+	if edits != 2 {
+		t.Fatalf("got %d edits, want 2", edits)
+	}
+}
+
+func TestTooDifferentTextsAreReplaced(t *testing.T) {
+	const n = maxEditDistance/2 + 100
+
+	from, to := numbered("a", n), numbered("b", n)
+
+	got := Unified(from, to)
+
+	want := fmt.Sprintf("@@ -1,%d +1,%d @@\n", n, n) +
+		"-" + strings.ReplaceAll(from, "\n", "\n-") + "\n" +
+		"+" + strings.ReplaceAll(to, "\n", "\n+") + "\n"
+
+	if got != want {
+		t.Fatalf("texts beyond maxEditDistance must be replaced as a whole, got %d bytes", len(got))
+	}
+}
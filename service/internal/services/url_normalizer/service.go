@@ -0,0 +1,100 @@
+package url_normalizer
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"strings"
+)
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// Параметры запроса, которые добавляют рекламные и аналитические системы
+var trackingParams = map[string]struct{}{
+	"gclid": {}, "dclid": {}, "fbclid": {}, "yclid": {}, "ysclid": {}, "msclkid": {}, "igshid": {},
+	"_openstat": {}, "_ga": {}, "_gl": {}, "mc_cid": {}, "mc_eid": {},
+}
+
+// Normalize приводит URL к каноническому виду: схема и хост в нижнем регистре, без порта
+// по умолчанию, фрагмента, завершающего слэша и параметров отслеживания, с отсортированными параметрами
+func Normalize(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+
+	if !strings.Contains(raw, "://") {
+		// mailto:, tel:, javascript: и подобные ссылки не ведут на страницы
+		if u, err := url.Parse(raw); err == nil && u.Opaque != "" && !strings.Contains(u.Scheme, ".") {
+			return "", fmt.Errorf("unsupported scheme [%s]", u.Scheme)
+		}
+
+		raw = "http://" + strings.TrimPrefix(raw, "//")
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	if _, ok := defaultPorts[u.Scheme]; !ok {
+		return "", fmt.Errorf("unsupported scheme [%s]", u.Scheme)
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return "", fmt.Errorf("empty host in url [%s]", raw)
+	}
+
+	if port := u.Port(); port != "" && port != defaultPorts[u.Scheme] {
+		host = net.JoinHostPort(host, port)
+	} else if strings.Contains(host, ":") {
+		// IPv6
+		host = "[" + host + "]"
+	}
+
+	u.Host = host
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	u.Path = cleanPath(u.Path)
+	u.RawPath = ""
+
+	u.RawQuery = cleanQuery(u.Query())
+	u.ForceQuery = false
+
+	return u.String(), nil
+}
+
+// Key возвращает ключ для поиска дублей: http и https версии страницы считаются одной страницей
+func Key(raw string) (string, error) {
+	normalized, err := Normalize(raw)
+	if err != nil {
+		return "", err
+	}
+
+	_, key, _ := strings.Cut(normalized, "://")
+
+	return key, nil
+}
+
+func cleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+
+	return path.Clean("/" + p)
+}
+
+func cleanQuery(query url.Values) string {
+	for name := range query {
+		if _, ok := trackingParams[strings.ToLower(name)]; ok || strings.HasPrefix(strings.ToLower(name), "utm_") {
+			query.Del(name)
+		}
+	}
+
+	// Encode сортирует параметры по имени
+	return query.Encode()
+}
@@ -2,8 +2,10 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	"github.com/K1flar/crawlers/internal/models/launch"
+	"github.com/K1flar/crawlers/internal/models/page"
 	"github.com/K1flar/crawlers/internal/models/source"
 	"github.com/K1flar/crawlers/internal/models/task"
 )
@@ -16,15 +18,45 @@ type Tasks interface {
 	Create(ctx context.Context, params ToCreateTask) (int64, error)
 	SetStatus(ctx context.Context, id int64, status task.Status) error
 	Process(ctx context.Context, id int64) error
-	Update(ctx context.Context, params ToUpdateTask) error
+	FinishProcessing(ctx context.Context, id int64, status task.Status) (bool, error)
+	ClaimDue(ctx context.Context, now time.Time, next func(t task.Task) time.Time) (task.Task, bool, error)
+	ReleaseDue(ctx context.Context, id int64, claimedNextRunAt, dueAt time.Time) error
+	Update(ctx context.Context, id int64, apply func(t task.Task) (ToUpdateTask, error)) error
+	Delete(ctx context.Context, id int64) error
+	PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error)
 }
 
 type Sources interface {
 	Create(ctx context.Context, params []ToCreateSource) (map[string]int64, error)
 	Update(ctx context.Context, params []ToUpdateSource) (map[string]int64, error)
 	GetByURLs(ctx context.Context, urls []string) (map[string]source.Source, error)
+	GetKnownByTaskID(ctx context.Context, taskID int64) (map[string]page.Known, error)
+	GetVersions(ctx context.Context, sourceID int64) ([]source.Version, error)
 	GetByTaskID(ctx context.Context, taskID int64) ([]source.ForTask, error)
+	GetByLaunchID(ctx context.Context, launchID int64) ([]source.ForTask, error)
 	GetForProtocol(ctx context.Context, filter FilterForProtocol) ([]source.ForProtocol, error)
+	DeleteOrphaned(ctx context.Context, updatedBefore time.Time) (int64, error)
+	Rekey(ctx context.Context, limit int) (int, error)
+}
+
+type SourceContents interface {
+	Create(ctx context.Context, params []ToCreateSourceContent) error
+	Get(ctx context.Context, sourceID int64, launchID *int64) (source.Content, error)
+	GetLastBySourceIDs(ctx context.Context, sourceIDs []int64) (map[int64]source.Content, error)
+	Search(ctx context.Context, filter FilterSearchSources) ([]source.SearchHit, error)
+	Reindex(ctx context.Context, limit int) (int, error)
+}
+
+type SourceAliases interface {
+	Create(ctx context.Context, params []ToCreateSourceAlias) error
+	GetLastByTaskID(ctx context.Context, taskID int64) (map[int64][]string, error)
+	GetByLaunchID(ctx context.Context, launchID int64) (map[int64][]string, error)
+}
+
+type SourceLinks interface {
+	Create(ctx context.Context, params []ToCreateSourceLink) error
+	GetLastByTaskID(ctx context.Context, taskID int64) ([]source.Link, error)
+	GetByLaunchID(ctx context.Context, launchID int64) ([]source.Link, error)
 }
 
 type TaskSources interface {
@@ -36,4 +68,6 @@ type Launches interface {
 	Finish(ctx context.Context, params ToFinishLaunch) error
 	Get(ctx context.Context, id int64) (launch.Launch, error)
 	GetLastByTaskID(ctx context.Context, taskID int64) (launch.Launch, error)
+	GetByNumber(ctx context.Context, taskID, number int64) (launch.Launch, error)
+	SetSummary(ctx context.Context, id int64, summary launch.Summary) error
 }
@@ -2,12 +2,16 @@ package launches
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/K1flar/crawlers/internal/business_errors"
 	"github.com/K1flar/crawlers/internal/models/launch"
+	"github.com/K1flar/crawlers/internal/models/page"
 	"github.com/K1flar/crawlers/internal/storage"
 	"github.com/Masterminds/squirrel"
 	"github.com/jmoiron/sqlx"
@@ -32,9 +36,22 @@ const (
 	sourcesViewedCol = "sources_viewed"
 	statusCol        = "status"
 	errorCol         = "error"
+
+	sourcesNewCol       = "sources_new"
+	sourcesChangedCol   = "sources_changed"
+	sourcesUnchangedCol = "sources_unchanged"
+	sourcesGoneCol      = "sources_gone"
+	skippedCol          = "skipped"
+
+	summaryCol         = "summary"
+	summarySourceIDCol = "summary_source_id"
 )
 
-var readColumns = []string{idCol, numberCol, taskIDCol, startedAtCol, finishedAtCol, sourcesViewedCol, statusCol, errorCol}
+var readColumns = []string{
+	idCol, numberCol, taskIDCol, startedAtCol, finishedAtCol, sourcesViewedCol, statusCol, errorCol,
+	sourcesNewCol, sourcesChangedCol, sourcesUnchangedCol, sourcesGoneCol, skippedCol,
+	summaryCol, summarySourceIDCol,
+}
 
 type launchPG struct {
 	ID            int64      `db:"id"`
@@ -45,6 +62,15 @@ type launchPG struct {
 	SourcesViewed int64      `db:"sources_viewed"`
 	Status        string     `db:"status"`
 	Error         *string    `db:"error"`
+
+	SourcesNew       int64  `db:"sources_new"`
+	SourcesChanged   int64  `db:"sources_changed"`
+	SourcesUnchanged int64  `db:"sources_unchanged"`
+	SourcesGone      int64  `db:"sources_gone"`
+	Skipped          []byte `db:"skipped"`
+
+	Summary         *string `db:"summary"`
+	SummarySourceID *int64  `db:"summary_source_id"`
 }
 
 func NewStorage(db *sqlx.DB) *Storage {
@@ -96,11 +122,20 @@ func (s *Storage) Finish(ctx context.Context, params storage.ToFinishLaunch) err
 			sourcesViewedCol: params.SourcesViewed,
 			statusCol:        params.Status,
 			errorCol:         params.Error,
+
+			sourcesNewCol:       params.Stats.New,
+			sourcesChangedCol:   params.Stats.Changed,
+			sourcesUnchangedCol: params.Stats.Unchanged,
+			sourcesGoneCol:      params.Stats.Gone,
+			skippedCol:          marshalSkipped(params.Skipped),
 		}).
 		Where(squirrel.Eq{idCol: params.ID}).
 		MustSql()
 
 	res, err := s.db.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
 
 	rows, err := res.RowsAffected()
 	if err != nil {
@@ -114,6 +149,31 @@ func (s *Storage) Finish(ctx context.Context, params storage.ToFinishLaunch) err
 	return nil
 }
 
+func (s *Storage) SetSummary(ctx context.Context, id int64, summary launch.Summary) error {
+	query, args := pgSql.
+		Update(launchesTbl).
+		Set(summaryCol, summary.Text).
+		Set(summarySourceIDCol, summary.SourceID).
+		Where(squirrel.Eq{idCol: id}).
+		MustSql()
+
+	res, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return business_errors.EntityNotFound
+	}
+
+	return nil
+}
+
 func (s *Storage) Get(ctx context.Context, id int64) (launch.Launch, error) {
 	var res launchPG
 
@@ -137,13 +197,33 @@ func (s *Storage) GetLastByTaskID(ctx context.Context, taskID int64) (launch.Lau
 		Where(squirrel.Eq{taskIDCol: taskID}).
 		MustSql()
 
-	sql, args := pgSql.
+	query, args := pgSql.
 		Select(readColumns...).
 		From(launchesTbl).
 		Where("id = ("+subSql+")", subArgs...).
 		MustSql()
 
-	err := s.db.GetContext(ctx, &res, sql, args...)
+	err := s.db.GetContext(ctx, &res, query, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return launch.Launch{}, business_errors.EntityNotFound
+	}
+
+	return mapFromPG(res), err
+}
+
+func (s *Storage) GetByNumber(ctx context.Context, taskID, number int64) (launch.Launch, error) {
+	var res launchPG
+
+	query, args := pgSql.
+		Select(readColumns...).
+		From(launchesTbl).
+		Where(squirrel.Eq{taskIDCol: taskID, numberCol: number}).
+		MustSql()
+
+	err := s.db.GetContext(ctx, &res, query, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return launch.Launch{}, business_errors.EntityNotFound
+	}
 
 	return mapFromPG(res), err
 }
@@ -158,9 +238,46 @@ func mapFromPG(pg launchPG) launch.Launch {
 		SourcesViewed: pg.SourcesViewed,
 		Status:        launch.Status(pg.Status),
 		Error:         (*launch.ErrorSlug)(pg.Error),
+		Stats: launch.Stats{
+			New:       pg.SourcesNew,
+			Changed:   pg.SourcesChanged,
+			Unchanged: pg.SourcesUnchanged,
+			Gone:      pg.SourcesGone,
+		},
+		Skipped: skippedFromPG(pg.Skipped),
+		Summary: mapSummaryFromPG(pg),
 	}
 }
 
+func mapSummaryFromPG(pg launchPG) *launch.Summary {
+	if pg.Summary == nil || pg.SummarySourceID == nil {
+		return nil
+	}
+
+	return &launch.Summary{
+		SourceID: *pg.SummarySourceID,
+		Text:     *pg.Summary,
+	}
+}
+
+func marshalSkipped(skipped map[page.SkipReason]int64) string {
+	if skipped == nil {
+		skipped = map[page.SkipReason]int64{}
+	}
+
+	b, _ := json.Marshal(skipped)
+
+	return string(b)
+}
+
+func skippedFromPG(raw []byte) map[page.SkipReason]int64 {
+	res := map[page.SkipReason]int64{}
+
+	_ = json.Unmarshal(raw, &res)
+
+	return res
+}
+
 func returning(cols ...string) string {
 	return "returning " + strings.Join(cols, ", ")
 }
@@ -4,24 +4,20 @@ import (
 	"time"
 
 	"github.com/K1flar/crawlers/internal/models/launch"
+	"github.com/K1flar/crawlers/internal/models/page"
 	"github.com/K1flar/crawlers/internal/models/source"
 	"github.com/K1flar/crawlers/internal/models/task"
 )
 
 type ToCreateTask struct {
-	Query                  string
-	DepthLevel             int64
-	MinWeight              int64
-	MaxSources             int64
-	MaxNeighboursForSource int64
+	Query     string
+	Config    task.Config
+	NextRunAt time.Time
 }
 
 type ToUpdateTask struct {
-	ID                     int64
-	DepthLevel             *int
-	MinWeight              *float64
-	MaxSources             *int64
-	MaxNeighboursForSource *int64
+	Config    task.Config
+	NextRunAt *time.Time // задается при смене расписания
 }
 
 type FilterTaskForList struct {
@@ -42,17 +38,28 @@ type FilterForProtocol struct {
 }
 
 type ToCreateSource struct {
-	Title     string
-	URL       string
-	CreatedAt time.Time
-	Status    source.Status
+	LaunchID     int64
+	Title        string
+	URL          string
+	CreatedAt    time.Time
+	Status       source.Status
+	ETag         string
+	LastModified string
+	ContentHash  string
+	Metadata     page.Metadata
 }
 
 type ToUpdateSource struct {
-	ID        int64
-	Title     string
-	Status    source.Status
-	UpdatedAt time.Time
+	ID           int64
+	URL          string // адрес, по которому источник найден, ключ результата Update
+	LaunchID     int64
+	Title        string
+	Status       source.Status
+	UpdatedAt    time.Time
+	ETag         string
+	LastModified string
+	ContentHash  string
+	Metadata     page.Metadata
 }
 
 type ToCreateLaunch struct {
@@ -66,6 +73,25 @@ type ToFinishLaunch struct {
 	SourcesViewed int64
 	Status        launch.Status
 	Error         *launch.ErrorSlug
+	Stats         launch.Stats
+	Skipped       map[page.SkipReason]int64
+}
+
+type ToCreateSourceContent struct {
+	SourceID  int64
+	LaunchID  int64
+	Title     string
+	Text      string
+	Hash      string
+	CreatedAt time.Time
+	Language  string // язык задачи; при auto язык определяется по тексту страницы
+}
+
+type FilterSearchSources struct {
+	Query  string
+	TaskID *int64
+	Limit  int64
+	Offset int64
 }
 
 type ToCreateTaskSource struct {
@@ -74,4 +100,25 @@ type ToCreateTaskSource struct {
 	SourceID       int64
 	ParentSourceID *int64
 	Weight         float64
+	Scorer         string
+	Graph          source.GraphScores
+}
+
+type ToCreateSourceAlias struct {
+	LaunchID  int64
+	SourceID  int64
+	URL       string
+	Distance  int
+	CreatedAt time.Time
+}
+
+type ToCreateSourceLink struct {
+	LaunchID     int64
+	FromSourceID int64
+	ToSourceID   *int64 // nil, если цель ссылки не сохранена в базе знаний
+	ToURL        string
+	Anchor       string
+	Rel          []string
+	Snippet      string
+	CreatedAt    time.Time
 }
@@ -0,0 +1,89 @@
+package source_aliases
+
+import (
+	"context"
+
+	"github.com/K1flar/crawlers/internal/storage"
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+var _ storage.SourceAliases = (*Storage)(nil)
+
+type Storage struct {
+	db *sqlx.DB
+}
+
+var pgSql = squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+
+const (
+	sourceAliasesTbl = "source_aliases"
+
+	launchIDCol  = "launch_id"
+	sourceIDCol  = "source_id"
+	urlCol       = "url"
+	distanceCol  = "distance"
+	createdAtCol = "created_at"
+)
+
+type aliasPG struct {
+	SourceID int64  `db:"source_id"`
+	URL      string `db:"url"`
+}
+
+func NewStorage(db *sqlx.DB) *Storage {
+	return &Storage{db}
+}
+
+func (s *Storage) Create(ctx context.Context, params []storage.ToCreateSourceAlias) error {
+	if len(params) == 0 {
+		return nil
+	}
+
+	q := pgSql.
+		Insert(sourceAliasesTbl).
+		Columns(launchIDCol, sourceIDCol, urlCol, distanceCol, createdAtCol)
+
+	for _, p := range params {
+		q = q.Values(p.LaunchID, p.SourceID, p.URL, p.Distance, p.CreatedAt)
+	}
+
+	sql, args := q.Suffix("ON CONFLICT (launch_id, source_id, url) DO NOTHING").MustSql()
+
+	_, err := s.db.ExecContext(ctx, sql, args...)
+
+	return err
+}
+
+// GetLastByTaskID возвращает адреса дублей источников последнего запуска задачи
+func (s *Storage) GetLastByTaskID(ctx context.Context, taskID int64) (map[int64][]string, error) {
+	return s.get(ctx, squirrel.Expr("launch_id = (SELECT MAX(id) FROM launches WHERE task_id = ?)", taskID))
+}
+
+// GetByLaunchID возвращает адреса дублей источников запуска
+func (s *Storage) GetByLaunchID(ctx context.Context, launchID int64) (map[int64][]string, error) {
+	return s.get(ctx, squirrel.Eq{launchIDCol: launchID})
+}
+
+func (s *Storage) get(ctx context.Context, where squirrel.Sqlizer) (map[int64][]string, error) {
+	var res []aliasPG
+
+	sql, args := pgSql.
+		Select(sourceIDCol, urlCol).
+		From(sourceAliasesTbl).
+		Where(where).
+		OrderBy(sourceIDCol, distanceCol, urlCol).
+		MustSql()
+
+	err := s.db.SelectContext(ctx, &res, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[int64][]string)
+	for _, alias := range res {
+		out[alias.SourceID] = append(out[alias.SourceID], alias.URL)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,358 @@
+package source_contents
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/K1flar/crawlers/internal/business_errors"
+	"github.com/K1flar/crawlers/internal/models/source"
+	"github.com/K1flar/crawlers/internal/services/analyzer"
+	"github.com/K1flar/crawlers/internal/storage"
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+var _ storage.SourceContents = (*Storage)(nil)
+
+type Storage struct {
+	db *sqlx.DB
+}
+
+var pgSql = squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+
+const (
+	sourceContentsTbl = "source_contents"
+
+	sourceIDCol    = "source_id"
+	launchIDCol    = "launch_id"
+	contentCol     = "content"
+	contentHashCol = "content_hash"
+	createdAtCol   = "created_at"
+	tsvCol         = "tsv"
+	tsvIndexedCol  = "tsv_indexed"
+
+	// Postgres не принимает tsvector больше 1 МБ, поэтому индексируется только начало текста страницы
+	maxIndexedTextLength = 100_000
+)
+
+var readColumns = []string{sourceIDCol, launchIDCol, contentCol, contentHashCol, createdAtCol}
+
+type sourceContentPG struct {
+	SourceID    int64     `db:"source_id"`
+	LaunchID    int64     `db:"launch_id"`
+	Content     []byte    `db:"content"`
+	ContentHash string    `db:"content_hash"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+func NewStorage(db *sqlx.DB) *Storage {
+	return &Storage{db}
+}
+
+func (s *Storage) Create(ctx context.Context, params []storage.ToCreateSourceContent) error {
+	if len(params) == 0 {
+		return nil
+	}
+
+	q := pgSql.
+		Insert(sourceContentsTbl).
+		Columns(sourceIDCol, launchIDCol, contentCol, contentHashCol, createdAtCol, tsvCol, tsvIndexedCol)
+
+	for _, p := range params {
+		content, err := compress(p.Text)
+		if err != nil {
+			return fmt.Errorf("failed to compress content of source [%d]: %w", p.SourceID, err)
+		}
+
+		q = q.Values(p.SourceID, p.LaunchID, content, p.Hash, p.CreatedAt, tsvExpr(p.Language, p.Title, p.Text), true)
+	}
+
+	sql, args := q.Suffix("ON CONFLICT (source_id, launch_id) DO NOTHING").MustSql()
+
+	_, err := s.db.ExecContext(ctx, sql, args...)
+
+	return err
+}
+
+// Get возвращает текст источника на момент запуска, без запуска - последний сохраненный.
+// Неизменившийся текст не сохраняется повторно, поэтому берется последняя запись не позже запуска
+func (s *Storage) Get(ctx context.Context, sourceID int64, launchID *int64) (source.Content, error) {
+	var res sourceContentPG
+
+	q := pgSql.
+		Select(readColumns...).
+		From(sourceContentsTbl).
+		Where(squirrel.Eq{sourceIDCol: sourceID})
+
+	if launchID != nil {
+		q = q.Where(squirrel.LtOrEq{launchIDCol: *launchID})
+	}
+
+	query, args := q.OrderBy(launchIDCol + " DESC").Limit(1).MustSql()
+
+	err := s.db.GetContext(ctx, &res, query, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return source.Content{}, business_errors.EntityNotFound
+	}
+	if err != nil {
+		return source.Content{}, err
+	}
+
+	return mapFromPG(res)
+}
+
+func (s *Storage) GetLastBySourceIDs(ctx context.Context, sourceIDs []int64) (map[int64]source.Content, error) {
+	if len(sourceIDs) == 0 {
+		return map[int64]source.Content{}, nil
+	}
+
+	var res []sourceContentPG
+
+	query, args := pgSql.
+		Select(readColumns...).
+		Options("DISTINCT ON ("+sourceIDCol+")").
+		From(sourceContentsTbl).
+		Where(squirrel.Eq{sourceIDCol: sourceIDs}).
+		OrderBy(sourceIDCol, launchIDCol+" DESC").
+		MustSql()
+
+	err := s.db.SelectContext(ctx, &res, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[int64]source.Content, len(res))
+	for _, pg := range res {
+		content, err := mapFromPG(pg)
+		if err != nil {
+			return nil, err
+		}
+
+		out[pg.SourceID] = content
+	}
+
+	return out, nil
+}
+
+type toReindexPG struct {
+	SourceID int64  `db:"source_id"`
+	LaunchID int64  `db:"launch_id"`
+	Title    string `db:"title"`
+	Content  []byte `db:"content"`
+}
+
+// Reindex пересчитывает поисковый индекс не более limit записей, проиндексированных без текста страницы,
+// и возвращает количество обработанных записей
+func (s *Storage) Reindex(ctx context.Context, limit int) (int, error) {
+	var res []toReindexPG
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query, args := pgSql.
+		Select("sc.source_id", "sc.launch_id", "s.title", "sc.content").
+		From(sourceContentsTbl+" sc").
+		Join("sources s ON s.id = sc.source_id").
+		Where("NOT sc."+tsvIndexedCol).
+		OrderBy("sc.source_id", "sc.launch_id").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE OF sc SKIP LOCKED").
+		MustSql()
+
+	err = tx.SelectContext(ctx, &res, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, pg := range res {
+		text, err := decompress(pg.Content)
+		if err != nil {
+			return 0, fmt.Errorf("failed to decompress content of source [%d]: %w", pg.SourceID, err)
+		}
+
+		query, args := pgSql.
+			Update(sourceContentsTbl).
+			Set(tsvCol, tsvExpr("", pg.Title, text)).
+			Set(tsvIndexedCol, true).
+			Where(squirrel.Eq{sourceIDCol: pg.SourceID, launchIDCol: pg.LaunchID}).
+			MustSql()
+
+		_, err = tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return len(res), nil
+}
+
+// Заголовок весит больше текста страницы при ранжировании
+func tsvExpr(lang, title, text string) squirrel.Sqlizer {
+	config := tsConfig(lang, title+" "+text)
+
+	return squirrel.Expr(
+		fmt.Sprintf(
+			"setweight(to_tsvector(?::regconfig, ?), 'A') || setweight(to_tsvector(?::regconfig, left(?, %d)), 'D')",
+			maxIndexedTextLength,
+		),
+		config, title, config, text,
+	)
+}
+
+// tsConfig возвращает конфигурацию полнотекстового поиска Postgres для языка страницы.
+// Названия языков анализатора совпадают с названиями конфигураций
+func tsConfig(language, text string) string {
+	lang, err := analyzer.ParseLanguage(language)
+	if err != nil || lang == analyzer.Auto {
+		return string(analyzer.Detect(text))
+	}
+
+	return string(lang)
+}
+
+type searchHitPG struct {
+	SourceID     int64   `db:"source_id"`
+	URL          string  `db:"url"`
+	Title        string  `db:"title"`
+	TaskID       int64   `db:"task_id"`
+	Query        string  `db:"query"`
+	LaunchID     int64   `db:"launch_id"`
+	LaunchNumber int64   `db:"launch_number"`
+	Rank         float64 `db:"rank"`
+	Content      []byte  `db:"content"`
+}
+
+// Search ищет источники по тексту; для каждой пары задача-источник берется последний запуск
+func (s *Storage) Search(ctx context.Context, filter storage.FilterSearchSources) ([]source.SearchHit, error) {
+	var res []searchHitPG
+
+	// Неизменившийся текст не сохраняется повторно, поэтому для запуска берется последняя запись не позже него
+	inner := pgSql.
+		Select(
+			"DISTINCT ON (txs.task_id, txs.source_id) txs.source_id", "s.url", "s.title",
+			"t.id AS task_id", "t.query",
+			"txs.launch_id", "l.number AS launch_number",
+			"ts_rank_cd(sc.tsv, q) AS rank", "sc.content",
+		).
+		From("tasks_x_sources txs").
+		Join("tasks t ON t.id = txs.task_id AND t.deleted_at IS NULL").
+		Join("launches l ON l.id = txs.launch_id").
+		Join("sources s ON s.id = txs.source_id").
+		JoinClause(`CROSS JOIN LATERAL (
+			SELECT tsv, content FROM source_contents
+			WHERE source_id = txs.source_id AND launch_id <= txs.launch_id
+			ORDER BY launch_id DESC
+			LIMIT 1
+		) sc`).
+		// Страницы проиндексированы на своем языке, поэтому запрос разбирается конфигурациями обоих языков
+		JoinClause(
+			"CROSS JOIN (websearch_to_tsquery('"+string(analyzer.Russian)+"', ?) || websearch_to_tsquery('"+string(analyzer.English)+"', ?)) q",
+			filter.Query, filter.Query,
+		).
+		Where("sc.tsv @@ q").
+		OrderBy("txs.task_id", "txs.source_id", "txs.launch_id DESC")
+
+	if filter.TaskID != nil {
+		inner = inner.Where(squirrel.Eq{"txs.task_id": *filter.TaskID})
+	}
+
+	q := pgSql.
+		Select("*").
+		FromSelect(inner, "hits").
+		OrderBy("rank DESC", "source_id")
+
+	if filter.Limit > 0 {
+		q = q.Limit(uint64(filter.Limit))
+	}
+
+	if filter.Offset > 0 {
+		q = q.Offset(uint64(filter.Offset))
+	}
+
+	query, args := q.MustSql()
+
+	err := s.db.SelectContext(ctx, &res, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]source.SearchHit, 0, len(res))
+	for _, pg := range res {
+		text, err := decompress(pg.Content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress content of source [%d]: %w", pg.SourceID, err)
+		}
+
+		hits = append(hits, source.SearchHit{
+			SourceID:     pg.SourceID,
+			URL:          pg.URL,
+			Title:        pg.Title,
+			TaskID:       pg.TaskID,
+			Query:        pg.Query,
+			LaunchID:     pg.LaunchID,
+			LaunchNumber: pg.LaunchNumber,
+			Rank:         pg.Rank,
+			Content:      text,
+		})
+	}
+
+	return hits, nil
+}
+
+func mapFromPG(pg sourceContentPG) (source.Content, error) {
+	text, err := decompress(pg.Content)
+	if err != nil {
+		return source.Content{}, fmt.Errorf("failed to decompress content of source [%d]: %w", pg.SourceID, err)
+	}
+
+	return source.Content{
+		SourceID:  pg.SourceID,
+		LaunchID:  pg.LaunchID,
+		Text:      text,
+		Hash:      pg.ContentHash,
+		CreatedAt: pg.CreatedAt,
+	}, nil
+}
+
+func compress(text string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte(text)); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func decompress(content []byte) (string, error) {
+	r, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
@@ -0,0 +1,111 @@
+package source_contents
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/K1flar/crawlers/internal/storage"
+	"github.com/jmoiron/sqlx"
+)
+
+func newMock(t *testing.T) (*Storage, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewStorage(sqlx.NewDb(db, "postgres")), mock
+}
+
+func TestReindexIndexesDecompressedText(t *testing.T) {
+	s, mock := newMock(t)
+
+	content, err := compress("текст страницы")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT sc.source_id, sc.launch_id, s.title, sc.content FROM source_contents sc .* WHERE NOT sc.tsv_indexed .* LIMIT 10 FOR UPDATE OF sc SKIP LOCKED`).
+		WillReturnRows(sqlmock.NewRows([]string{"source_id", "launch_id", "title", "content"}).AddRow(1, 2, "заголовок", content))
+	mock.ExpectExec(`UPDATE source_contents SET tsv = .*left\(\$4, 100000\).*, tsv_indexed = \$5 WHERE launch_id = \$6 AND source_id = \$7`).
+		WithArgs("russian", "заголовок", "russian", "текст страницы", true, int64(2), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	count, err := s.Reindex(context.Background(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 1 {
+		t.Fatalf("got %d reindexed, want 1", count)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCreateIndexesTruncatedTextInPageLanguage(t *testing.T) {
+	s, mock := newMock(t)
+
+	// Для auto язык определяется по тексту страницы, иначе берется язык задачи
+	mock.ExpectExec(`INSERT INTO source_contents .* ON CONFLICT \(source_id, launch_id\) DO NOTHING`).
+		WithArgs(
+			int64(1), int64(2), sqlmock.AnyArg(), "h1", sqlmock.AnyArg(), "english", "Title", "english", "page text", true,
+			int64(3), int64(2), sqlmock.AnyArg(), "h3", sqlmock.AnyArg(), "russian", "Title", "russian", "page text", true,
+		).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	err := s.Create(context.Background(), []storage.ToCreateSourceContent{
+		{SourceID: 1, LaunchID: 2, Title: "Title", Text: "page text", Hash: "h1", Language: "auto"},
+		{SourceID: 3, LaunchID: 2, Title: "Title", Text: "page text", Hash: "h3", Language: "russian"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTsvExprTruncatesText(t *testing.T) {
+	query, _, err := tsvExpr("english", "title", "text").ToSql()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(query, "to_tsvector(?::regconfig, left(?, 100000))") {
+		t.Fatalf("text is not truncated: %s", query)
+	}
+}
+
+func TestSearchTakesContentNotLaterThanLaunch(t *testing.T) {
+	s, mock := newMock(t)
+
+	content, err := compress("текст")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Связь задачи с источником не требует записи текста в том же запуске
+	mock.ExpectQuery(`FROM tasks_x_sources txs .* CROSS JOIN LATERAL \(\s*SELECT tsv, content FROM source_contents\s*WHERE source_id = txs.source_id AND launch_id <= txs.launch_id\s*ORDER BY launch_id DESC\s*LIMIT 1\s*\) sc`).
+		WillReturnRows(sqlmock.NewRows([]string{"source_id", "url", "title", "task_id", "query", "launch_id", "launch_number", "rank", "content"}).
+			AddRow(1, "https://example.com", "t", 3, "q", 5, 2, 0.5, content))
+
+	hits, err := s.Search(context.Background(), storage.FilterSearchSources{Query: "текст"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(hits) != 1 || hits[0].LaunchID != 5 || hits[0].Content != "текст" {
+		t.Fatalf("unexpected hits %+v", hits)
+	}
+}
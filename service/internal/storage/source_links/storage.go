@@ -0,0 +1,103 @@
+package source_links
+
+import (
+	"context"
+
+	"github.com/K1flar/crawlers/internal/models/source"
+	"github.com/K1flar/crawlers/internal/storage"
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/samber/lo"
+)
+
+var _ storage.SourceLinks = (*Storage)(nil)
+
+type Storage struct {
+	db *sqlx.DB
+}
+
+var pgSql = squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+
+const (
+	sourceLinksTbl = "source_links"
+
+	launchIDCol     = "launch_id"
+	fromSourceIDCol = "from_source_id"
+	toSourceIDCol   = "to_source_id"
+	toURLCol        = "to_url"
+	anchorCol       = "anchor"
+	relCol          = "rel"
+	snippetCol      = "snippet"
+	createdAtCol    = "created_at"
+)
+
+type linkPG struct {
+	FromSourceID int64          `db:"from_source_id"`
+	ToSourceID   *int64         `db:"to_source_id"`
+	ToURL        string         `db:"to_url"`
+	Anchor       string         `db:"anchor"`
+	Rel          pq.StringArray `db:"rel"`
+	Snippet      string         `db:"snippet"`
+}
+
+func NewStorage(db *sqlx.DB) *Storage {
+	return &Storage{db}
+}
+
+func (s *Storage) Create(ctx context.Context, params []storage.ToCreateSourceLink) error {
+	if len(params) == 0 {
+		return nil
+	}
+
+	q := pgSql.
+		Insert(sourceLinksTbl).
+		Columns(launchIDCol, fromSourceIDCol, toSourceIDCol, toURLCol, anchorCol, relCol, snippetCol, createdAtCol)
+
+	for _, p := range params {
+		q = q.Values(p.LaunchID, p.FromSourceID, p.ToSourceID, p.ToURL, p.Anchor, pq.StringArray(lo.CoalesceSliceOrEmpty(p.Rel)), p.Snippet, p.CreatedAt)
+	}
+
+	sql, args := q.Suffix("ON CONFLICT (launch_id, from_source_id, to_url) DO NOTHING").MustSql()
+
+	_, err := s.db.ExecContext(ctx, sql, args...)
+
+	return err
+}
+
+// GetLastByTaskID возвращает ссылки источников последнего запуска задачи
+func (s *Storage) GetLastByTaskID(ctx context.Context, taskID int64) ([]source.Link, error) {
+	return s.get(ctx, squirrel.Expr("launch_id = (SELECT MAX(id) FROM launches WHERE task_id = ?)", taskID))
+}
+
+// GetByLaunchID возвращает ссылки источников запуска
+func (s *Storage) GetByLaunchID(ctx context.Context, launchID int64) ([]source.Link, error) {
+	return s.get(ctx, squirrel.Eq{launchIDCol: launchID})
+}
+
+func (s *Storage) get(ctx context.Context, where squirrel.Sqlizer) ([]source.Link, error) {
+	var res []linkPG
+
+	sql, args := pgSql.
+		Select(fromSourceIDCol, toSourceIDCol, toURLCol, anchorCol, relCol, snippetCol).
+		From(sourceLinksTbl).
+		Where(where).
+		OrderBy(toURLCol, fromSourceIDCol).
+		MustSql()
+
+	err := s.db.SelectContext(ctx, &res, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return lo.Map(res, func(pg linkPG, _ int) source.Link {
+		return source.Link{
+			FromSourceID: pg.FromSourceID,
+			ToSourceID:   pg.ToSourceID,
+			ToURL:        pg.ToURL,
+			Anchor:       pg.Anchor,
+			Rel:          pg.Rel,
+			Snippet:      pg.Snippet,
+		}
+	}), nil
+}
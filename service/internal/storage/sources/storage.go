@@ -7,11 +7,14 @@ import (
 	"time"
 
 	"github.com/K1flar/crawlers/internal/models/launch"
+	"github.com/K1flar/crawlers/internal/models/page"
 	"github.com/K1flar/crawlers/internal/models/source"
+	"github.com/K1flar/crawlers/internal/services/url_normalizer"
 	"github.com/K1flar/crawlers/internal/storage"
 	"github.com/K1flar/crawlers/internal/utils"
 	"github.com/Masterminds/squirrel"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/samber/lo"
 )
 
@@ -24,27 +27,68 @@ type Storage struct {
 var pgSql = squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 
 const (
-	sourcesTbl  = "sources"
-	tasksTbl    = "tasks"
-	launchesTbl = "launches"
+	sourcesTbl        = "sources"
+	sourceVersionsTbl = "source_versions"
+	tasksTbl          = "tasks"
+	launchesTbl       = "launches"
 
 	idCol        = "id"
 	titleCol     = "title"
 	urlCol       = "url"
+	urlKeyCol    = "url_key"
 	statusCol    = "status"
 	createdAtCol = "created_at"
 	updatedAtCol = "updated_at"
+
+	etagCol         = "etag"
+	lastModifiedCol = "last_modified"
+	contentHashCol  = "content_hash"
+
+	descriptionCol  = "description"
+	languageCol     = "language"
+	canonicalURLCol = "canonical_url"
+	authorCol       = "author"
+	siteNameCol     = "site_name"
+	imageURLCol     = "image_url"
+	publishedAtCol  = "published_at"
+	modifiedAtCol   = "modified_at"
+
+	sourceIDCol = "source_id"
+	versionCol  = "version"
+	launchIDCol = "launch_id"
 )
 
-var readColumns = []string{idCol, titleCol, urlCol, statusCol, createdAtCol, updatedAtCol}
+var metadataColumns = []string{
+	descriptionCol, languageCol, canonicalURLCol, authorCol, siteNameCol, imageURLCol, publishedAtCol, modifiedAtCol,
+}
+
+var readColumns = append([]string{
+	idCol, titleCol, urlCol, statusCol, createdAtCol, updatedAtCol,
+	etagCol, lastModifiedCol, contentHashCol,
+}, metadataColumns...)
+
+type metadataPG struct {
+	Description  *string    `db:"description"`
+	Language     *string    `db:"language"`
+	CanonicalURL *string    `db:"canonical_url"`
+	Author       *string    `db:"author"`
+	SiteName     *string    `db:"site_name"`
+	ImageURL     *string    `db:"image_url"`
+	PublishedAt  *time.Time `db:"published_at"`
+	ModifiedAt   *time.Time `db:"modified_at"`
+}
 
 type sourcePG struct {
-	ID        int64     `db:"id"`
-	Title     string    `db:"title"`
-	URL       string    `db:"url"`
-	Status    string    `db:"status"`
-	CreatedAt time.Time `db:"created_at"`
-	UpdatedAt time.Time `db:"updated_at"`
+	ID           int64     `db:"id"`
+	Title        string    `db:"title"`
+	URL          string    `db:"url"`
+	Status       string    `db:"status"`
+	CreatedAt    time.Time `db:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at"`
+	ETag         *string   `db:"etag"`
+	LastModified *string   `db:"last_modified"`
+	ContentHash  *string   `db:"content_hash"`
+	metadataPG
 }
 
 func NewStorage(db *sqlx.DB) *Storage {
@@ -61,23 +105,120 @@ func (s *Storage) Create(ctx context.Context, params []storage.ToCreateSource) (
 		return map[string]int64{}, nil
 	}
 
+	// Разные записи одной страницы, в том числе ее http и https версии, сохраняются одним источником
+	inputURLs := make(map[string][]string, len(params))
+	params = lo.UniqBy(lo.Map(params, func(p storage.ToCreateSource, _ int) storage.ToCreateSource {
+		key := urlKey(p.URL)
+		inputURLs[key] = append(inputURLs[key], p.URL)
+		p.URL = normalizeURL(p.URL)
+
+		return p
+	}), func(p storage.ToCreateSource) string {
+		return urlKey(p.URL)
+	})
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	q := pgSql.
 		Insert(sourcesTbl).
-		Columns(titleCol, urlCol, statusCol, createdAtCol, updatedAtCol)
+		Columns(titleCol, urlCol, urlKeyCol, statusCol, createdAtCol, updatedAtCol, etagCol, lastModifiedCol, contentHashCol).
+		Columns(metadataColumns...)
 
 	for _, p := range params {
-		q = q.Values(p.Title, p.URL, p.Status, p.CreatedAt, p.CreatedAt)
+		q = q.Values(append([]any{
+			p.Title, p.URL, urlKey(p.URL), p.Status, p.CreatedAt, p.CreatedAt,
+			lo.EmptyableToPtr(p.ETag), lo.EmptyableToPtr(p.LastModified), lo.EmptyableToPtr(p.ContentHash),
+		}, metadataValues(p.Metadata)...)...)
 	}
 
-	sql, args := q.Suffix(returning(urlCol, idCol)).MustSql()
+	// Страницу мог успеть сохранить параллельный обработчик, тогда берется его источник
+	sql, args := q.Suffix("ON CONFLICT DO NOTHING " + returning(urlCol, idCol)).MustSql()
+
+	var created []key
 
-	var out []key
+	err = tx.SelectContext(ctx, &created, sql, args...)
+	if err != nil {
+		return nil, err
+	}
 
-	err := s.db.SelectContext(ctx, &out, sql, args...)
+	if len(created) > 0 {
+		paramsByURL := lo.SliceToMap(params, func(p storage.ToCreateSource) (string, storage.ToCreateSource) {
+			return p.URL, p
+		})
 
-	return lo.SliceToMap(out, func(key key) (string, int64) {
-		return key.URL, key.ID
-	}), err
+		versions := pgSql.
+			Insert(sourceVersionsTbl).
+			Columns(sourceIDCol, versionCol, launchIDCol, titleCol, statusCol, contentHashCol, createdAtCol)
+
+		for _, key := range created {
+			p := paramsByURL[key.URL]
+			versions = versions.Values(
+				key.ID, 1, lo.EmptyableToPtr(p.LaunchID), p.Title, p.Status, lo.EmptyableToPtr(p.ContentHash), p.CreatedAt,
+			)
+		}
+
+		sql, args = versions.MustSql()
+
+		if _, err := tx.ExecContext(ctx, sql, args...); err != nil {
+			return nil, fmt.Errorf("failed to create source versions: %w", err)
+		}
+	}
+
+	out := created
+
+	isCreated := lo.SliceToMap(created, func(k key) (string, struct{}) {
+		return urlKey(k.URL), struct{}{}
+	})
+	conflicted := lo.Filter(params, func(p storage.ToCreateSource, _ int) bool {
+		_, ok := isCreated[urlKey(p.URL)]
+		return !ok
+	})
+
+	if len(conflicted) > 0 {
+		var existed []key
+
+		sql, args = pgSql.
+			Select(urlCol, idCol).
+			From(sourcesTbl).
+			Where(squirrel.Or{
+				squirrel.Eq{urlKeyCol: lo.Map(conflicted, func(p storage.ToCreateSource, _ int) string { return urlKey(p.URL) })},
+				squirrel.Eq{urlCol: lo.Map(conflicted, func(p storage.ToCreateSource, _ int) string { return p.URL })},
+			}).
+			OrderBy(idCol).
+			MustSql()
+
+		if err := tx.SelectContext(ctx, &existed, sql, args...); err != nil {
+			return nil, fmt.Errorf("failed to get existed sources: %w", err)
+		}
+
+		out = append(out, existed...)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	res := make(map[string]int64, len(out))
+	// Из дублей, сохраненных до появления ключа, берется самый старый источник
+	for _, key := range out {
+		for _, url := range inputURLs[urlKey(key.URL)] {
+			if _, ok := res[url]; !ok {
+				res[url] = key.ID
+			}
+		}
+	}
+
+	return res, nil
+}
+
+type versionedPG struct {
+	Title       string  `db:"title"`
+	Status      string  `db:"status"`
+	ContentHash *string `db:"content_hash"`
 }
 
 func (s *Storage) Update(ctx context.Context, params []storage.ToUpdateSource) (map[string]int64, error) {
@@ -94,22 +235,63 @@ func (s *Storage) Update(ctx context.Context, params []storage.ToUpdateSource) (
 	defer tx.Rollback()
 
 	for _, param := range params {
+		var prev versionedPG
+
 		sql, args := pgSql.
+			Select(titleCol, statusCol, contentHashCol).
+			From(sourcesTbl).
+			Where(squirrel.Eq{idCol: param.ID}).
+			Suffix("FOR UPDATE").
+			MustSql()
+
+		err := tx.GetContext(ctx, &prev, sql, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get source [%d]: %w", param.ID, err)
+		}
+
+		sql, args = pgSql.
 			Update(sourcesTbl).
 			Set(titleCol, param.Title).
 			Set(statusCol, param.Status).
 			Set(updatedAtCol, param.UpdatedAt).
+			Set(etagCol, lo.EmptyableToPtr(param.ETag)).
+			Set(lastModifiedCol, lo.EmptyableToPtr(param.LastModified)).
+			Set(contentHashCol, lo.EmptyableToPtr(param.ContentHash)).
+			SetMap(metadataToPG(param.Metadata)).
 			Where(squirrel.Eq{idCol: param.ID}).
 			Suffix(returning(urlCol, idCol)).
 			MustSql()
 
 		var key key
-		err := tx.GetContext(ctx, &key, sql, args...)
+		err = tx.GetContext(ctx, &key, sql, args...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to execute update query: %w", err)
 		}
 
-		out[key.URL] = key.ID
+		// Источник, сохраненный до нормализации, хранится под другим адресом
+		out[param.URL] = key.ID
+
+		if prev.Title == param.Title && prev.Status == string(param.Status) && lo.FromPtr(prev.ContentHash) == param.ContentHash {
+			continue
+		}
+
+		sql, args = pgSql.
+			Insert(sourceVersionsTbl).
+			Columns(sourceIDCol, versionCol, launchIDCol, titleCol, statusCol, contentHashCol, createdAtCol).
+			Values(
+				param.ID,
+				squirrel.Expr("(SELECT COALESCE(MAX(version), 0) + 1 FROM source_versions WHERE source_id = ?)", param.ID),
+				lo.EmptyableToPtr(param.LaunchID),
+				param.Title,
+				param.Status,
+				lo.EmptyableToPtr(param.ContentHash),
+				param.UpdatedAt,
+			).
+			MustSql()
+
+		if _, err := tx.ExecContext(ctx, sql, args...); err != nil {
+			return nil, fmt.Errorf("failed to create version of source [%d]: %w", param.ID, err)
+		}
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -126,10 +308,19 @@ func (s *Storage) GetByURLs(ctx context.Context, urls []string) (map[string]sour
 
 	var res []sourcePG
 
+	// Ищем по ключу адреса без схемы, а также по исходным URL источников, сохраненных до нормализации
+	keys := lo.Map(urls, func(url string, _ int) string {
+		return urlKey(url)
+	})
+
 	sql, args := pgSql.
 		Select(readColumns...).
 		From(sourcesTbl).
-		Where(squirrel.Eq{urlCol: urls}).
+		Where(squirrel.Or{
+			squirrel.Eq{urlKeyCol: lo.Uniq(keys)},
+			squirrel.Eq{urlCol: lo.Uniq(urls)},
+		}).
+		OrderBy(idCol).
 		MustSql()
 
 	err := s.db.SelectContext(ctx, &res, sql, args...)
@@ -137,8 +328,125 @@ func (s *Storage) GetByURLs(ctx context.Context, urls []string) (map[string]sour
 		return nil, err
 	}
 
-	return lo.SliceToMap(res, func(source sourcePG) (string, source.Source) {
-		return source.URL, mapFromPG(source)
+	// Из дублей, сохраненных до появления ключа, берется самый старый источник
+	byKey := make(map[string]source.Source, len(res))
+	byURL := make(map[string]source.Source, len(res))
+	for _, pg := range res {
+		if _, ok := byKey[urlKey(pg.URL)]; !ok {
+			byKey[urlKey(pg.URL)] = mapFromPG(pg)
+		}
+
+		byURL[pg.URL] = mapFromPG(pg)
+	}
+
+	out := make(map[string]source.Source, len(urls))
+	for i, url := range urls {
+		if source, ok := byKey[keys[i]]; ok {
+			out[url] = source
+		} else if source, ok := byURL[url]; ok {
+			out[url] = source
+		}
+	}
+
+	return out, nil
+}
+
+// urlKey возвращает ключ, по которому http и https версии страницы считаются одним источником
+func urlKey(url string) string {
+	key, err := url_normalizer.Key(url)
+	if err != nil {
+		return url
+	}
+
+	return key
+}
+
+// normalizeURL оставляет URL как есть, если его не удалось нормализовать
+func normalizeURL(url string) string {
+	normalized, err := url_normalizer.Normalize(url)
+	if err != nil {
+		return url
+	}
+
+	return normalized
+}
+
+type sourceVersionPG struct {
+	ID          int64     `db:"id"`
+	SourceID    int64     `db:"source_id"`
+	Version     int64     `db:"version"`
+	LaunchID    *int64    `db:"launch_id"`
+	Title       string    `db:"title"`
+	Status      string    `db:"status"`
+	ContentHash *string   `db:"content_hash"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+func (s *Storage) GetVersions(ctx context.Context, sourceID int64) ([]source.Version, error) {
+	var res []sourceVersionPG
+
+	sql, args := pgSql.
+		Select(idCol, sourceIDCol, versionCol, launchIDCol, titleCol, statusCol, contentHashCol, createdAtCol).
+		From(sourceVersionsTbl).
+		Where(squirrel.Eq{sourceIDCol: sourceID}).
+		OrderBy(versionCol).
+		MustSql()
+
+	err := s.db.SelectContext(ctx, &res, sql, args...)
+
+	return lo.Map(res, func(pg sourceVersionPG, _ int) source.Version {
+		return source.Version{
+			ID:          pg.ID,
+			SourceID:    pg.SourceID,
+			Version:     pg.Version,
+			LaunchID:    pg.LaunchID,
+			Title:       pg.Title,
+			Status:      source.Status(pg.Status),
+			ContentHash: lo.FromPtr(pg.ContentHash),
+			CreatedAt:   pg.CreatedAt,
+		}
+	}), err
+}
+
+type knownSourcePG struct {
+	URL          string         `db:"url"`
+	ETag         *string        `db:"etag"`
+	LastModified *string        `db:"last_modified"`
+	ContentHash  *string        `db:"content_hash"`
+	ChildURLs    pq.StringArray `db:"child_urls"`
+}
+
+// GetKnownByTaskID возвращает источники последнего запуска задачи с валидаторами
+// и дочерними источниками для повторного обхода
+func (s *Storage) GetKnownByTaskID(ctx context.Context, taskID int64) (map[string]page.Known, error) {
+	var res []knownSourcePG
+
+	sql, args := pgSql.
+		Select(
+			"s.url", "s.etag", "s.last_modified", "s.content_hash",
+			"COALESCE(array_agg(cs.url) FILTER (WHERE cs.url IS NOT NULL), '{}') AS child_urls",
+		).
+		From("sources s").
+		Join("tasks_x_sources txs ON s.id = txs.source_id").
+		LeftJoin("tasks_x_sources ctxs ON ctxs.task_id = txs.task_id AND ctxs.launch_id = txs.launch_id AND ctxs.parent_source_id = s.id").
+		LeftJoin("sources cs ON cs.id = ctxs.source_id").
+		Where(squirrel.Eq{"txs.task_id": taskID}).
+		Where("txs.launch_id = (SELECT MAX(launch_id) FROM tasks_x_sources WHERE task_id = ?)", taskID).
+		GroupBy("s.id").
+		MustSql()
+
+	err := s.db.SelectContext(ctx, &res, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return lo.SliceToMap(res, func(pg knownSourcePG) (string, page.Known) {
+		return pg.URL, page.Known{
+			ETag:         lo.FromPtr(pg.ETag),
+			LastModified: lo.FromPtr(pg.LastModified),
+			ContentHash:  lo.FromPtr(pg.ContentHash),
+			URLs:         pg.ChildURLs,
+		}
 	}), nil
 }
 
@@ -147,7 +455,13 @@ type taskSourcePG struct {
 	URL      string  `db:"url"`
 	Title    string  `db:"title"`
 	Weight   float64 `db:"weight"`
+	Scorer   string  `db:"scorer"`
 	ParentID *int64  `db:"parent_source_id"`
+	metadataPG
+
+	PageRank       float64 `db:"pagerank"`
+	HubScore       float64 `db:"hub_score"`
+	AuthorityScore float64 `db:"authority_score"`
 }
 
 func (s *Storage) GetByTaskID(ctx context.Context, taskID int64) ([]source.ForTask, error) {
@@ -156,7 +470,8 @@ func (s *Storage) GetByTaskID(ctx context.Context, taskID int64) ([]source.ForTa
 	subSql := squirrel.Expr("txs.launch_id = (SELECT MAX(id) FROM launches WHERE task_id = ?)", taskID)
 
 	sql, args := pgSql.
-		Select("s.id", "s.title", "s.url", "txs.weight", "txs.parent_source_id").
+		Select("s.id", "s.title", "s.url", "txs.weight", "txs.scorer", "txs.parent_source_id", "txs.pagerank", "txs.hub_score", "txs.authority_score").
+		Columns(prefixed("s", metadataColumns)...).
 		From("sources s").
 		Join("tasks_x_sources txs ON s.id = txs.source_id").
 		Where(squirrel.Eq{"txs.task_id": taskID}).
@@ -165,15 +480,40 @@ func (s *Storage) GetByTaskID(ctx context.Context, taskID int64) ([]source.ForTa
 
 	err := s.db.SelectContext(ctx, &res, sql, args...)
 
-	return lo.Map(res, func(pg taskSourcePG, _ int) source.ForTask {
-		return source.ForTask{
-			ID:       pg.ID,
-			URL:      pg.URL,
-			Title:    pg.Title,
-			Weight:   pg.Weight,
-			ParentID: pg.ParentID,
-		}
-	}), err
+	return lo.Map(res, mapFromPGForTask), err
+}
+
+func (s *Storage) GetByLaunchID(ctx context.Context, launchID int64) ([]source.ForTask, error) {
+	var res []taskSourcePG
+
+	sql, args := pgSql.
+		Select("s.id", "s.title", "s.url", "txs.weight", "txs.scorer", "txs.parent_source_id", "txs.pagerank", "txs.hub_score", "txs.authority_score").
+		Columns(prefixed("s", metadataColumns)...).
+		From("sources s").
+		Join("tasks_x_sources txs ON s.id = txs.source_id").
+		Where(squirrel.Eq{"txs.launch_id": launchID}).
+		MustSql()
+
+	err := s.db.SelectContext(ctx, &res, sql, args...)
+
+	return lo.Map(res, mapFromPGForTask), err
+}
+
+func mapFromPGForTask(pg taskSourcePG, _ int) source.ForTask {
+	return source.ForTask{
+		ID:       pg.ID,
+		URL:      pg.URL,
+		Title:    pg.Title,
+		Weight:   pg.Weight,
+		Scorer:   pg.Scorer,
+		ParentID: pg.ParentID,
+		Metadata: mapMetadataFromPG(pg.metadataPG),
+		Graph: source.GraphScores{
+			PageRank:  pg.PageRank,
+			Hub:       pg.HubScore,
+			Authority: pg.AuthorityScore,
+		},
+	}
 }
 
 type sourceForProtocolPG struct {
@@ -191,6 +531,7 @@ type sourceForProtocolPG struct {
 	FinishedAt      *time.Time `db:"finished_at"`
 	LaunchStatus    string     `db:"launch_status"`
 	LaunchErrorSlug *string    `db:"error"`
+	metadataPG
 }
 
 func (s *Storage) GetForProtocol(ctx context.Context, filter storage.FilterForProtocol) ([]source.ForProtocol, error) {
@@ -201,9 +542,10 @@ func (s *Storage) GetForProtocol(ctx context.Context, filter storage.FilterForPr
 			"s.id as source_id", "s.title", "s.url", "s.created_at", "s.updated_at", "s.status as source_status",
 			"l.id as launch_id", "l.number as launch_number", "l.started_at",
 			"finished_at", "l.status as launch_status", "l.error").
+		Columns(prefixed("s", metadataColumns)...).
 		From("sources s").
 		Join("tasks_x_sources txs ON s.id = txs.source_id").
-		Join("tasks t ON t.id = txs.task_id").
+		Join("tasks t ON t.id = txs.task_id AND t.deleted_at IS NULL").
 		Join("launches l ON t.id = l.task_id")
 
 	if filter.TaskID != nil {
@@ -259,6 +601,7 @@ func (s *Storage) GetForProtocol(ctx context.Context, filter storage.FilterForPr
 			Duration:        duration,
 			LaunchStatus:    launch.Status(s.LaunchStatus),
 			LaunchErrorSlug: s.LaunchErrorSlug,
+			Metadata:        mapMetadataFromPG(s.metadataPG),
 		}
 	}), err
 }
@@ -271,15 +614,193 @@ func mapFromPGMany(sources []sourcePG) []source.Source {
 
 func mapFromPG(pg sourcePG) source.Source {
 	return source.Source{
-		ID:        pg.ID,
-		Title:     pg.Title,
-		URL:       pg.URL,
-		Status:    source.Status(pg.Status),
-		CreatedAt: pg.CreatedAt,
-		UpdatedAt: pg.UpdatedAt,
+		ID:           pg.ID,
+		Title:        pg.Title,
+		URL:          pg.URL,
+		Status:       source.Status(pg.Status),
+		CreatedAt:    pg.CreatedAt,
+		UpdatedAt:    pg.UpdatedAt,
+		ETag:         lo.FromPtr(pg.ETag),
+		LastModified: lo.FromPtr(pg.LastModified),
+		ContentHash:  lo.FromPtr(pg.ContentHash),
+		Metadata:     mapMetadataFromPG(pg.metadataPG),
+	}
+}
+
+func mapMetadataFromPG(pg metadataPG) page.Metadata {
+	return page.Metadata{
+		Description: lo.FromPtr(pg.Description),
+		Language:    lo.FromPtr(pg.Language),
+		Canonical:   lo.FromPtr(pg.CanonicalURL),
+		Author:      lo.FromPtr(pg.Author),
+		SiteName:    lo.FromPtr(pg.SiteName),
+		Image:       lo.FromPtr(pg.ImageURL),
+		PublishedAt: pg.PublishedAt,
+		ModifiedAt:  pg.ModifiedAt,
+	}
+}
+
+func metadataToPG(m page.Metadata) map[string]any {
+	return map[string]any{
+		descriptionCol:  lo.EmptyableToPtr(m.Description),
+		languageCol:     lo.EmptyableToPtr(m.Language),
+		canonicalURLCol: lo.EmptyableToPtr(m.Canonical),
+		authorCol:       lo.EmptyableToPtr(m.Author),
+		siteNameCol:     lo.EmptyableToPtr(m.SiteName),
+		imageURLCol:     lo.EmptyableToPtr(m.Image),
+		publishedAtCol:  m.PublishedAt,
+		modifiedAtCol:   m.ModifiedAt,
 	}
 }
 
+// metadataValues возвращает значения в порядке metadataColumns
+func metadataValues(m page.Metadata) []any {
+	values := metadataToPG(m)
+
+	return lo.Map(metadataColumns, func(col string, _ int) any {
+		return values[col]
+	})
+}
+
+func prefixed(alias string, cols []string) []string {
+	return lo.Map(cols, func(col string, _ int) string {
+		return alias + "." + col
+	})
+}
+
 func returning(cols ...string) string {
 	return "returning " + strings.Join(cols, ", ")
 }
+
+// DeleteOrphaned удаляет источники, которые не входят ни в один запуск задач. Источники,
+// обновленные позже updatedBefore, не удаляются: идущий запуск мог еще не привязать их к задаче
+func (s *Storage) DeleteOrphaned(ctx context.Context, updatedBefore time.Time) (int64, error) {
+	sql, args := pgSql.
+		Delete("sources s").
+		Where(squirrel.Lt{"s.updated_at": updatedBefore}).
+		Where("NOT EXISTS (SELECT 1 FROM tasks_x_sources txs WHERE txs.source_id = s.id)").
+		MustSql()
+
+	res, err := s.db.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// mergeQueries переносят данные дубля from в источник to с тем же ключом адреса.
+// Записи, которые у источника уже есть, удаляются вместе с дублем
+func mergeQueries(from, to int64) []squirrel.Sqlizer {
+	return []squirrel.Sqlizer{
+		pgSql.
+			Update("tasks_x_sources t").
+			Set(sourceIDCol, to).
+			Where(squirrel.Eq{sourceIDCol: from}).
+			Where("NOT EXISTS (SELECT 1 FROM tasks_x_sources o WHERE o.task_id = t.task_id AND o.launch_id = t.launch_id AND o.source_id = ?)", to),
+		pgSql.
+			Update("tasks_x_sources").
+			Set("parent_source_id", to).
+			Where(squirrel.Eq{"parent_source_id": from}),
+		pgSql.
+			Update("source_contents c").
+			Set(sourceIDCol, to).
+			Where(squirrel.Eq{sourceIDCol: from}).
+			Where("NOT EXISTS (SELECT 1 FROM source_contents o WHERE o.launch_id = c.launch_id AND o.source_id = ?)", to),
+		pgSql.
+			Update("source_aliases a").
+			Set(sourceIDCol, to).
+			Where(squirrel.Eq{sourceIDCol: from}).
+			Where("NOT EXISTS (SELECT 1 FROM source_aliases o WHERE o.launch_id = a.launch_id AND o.url = a.url AND o.source_id = ?)", to),
+		pgSql.
+			Update("source_links l").
+			Set("from_source_id", to).
+			Where(squirrel.Eq{"from_source_id": from}).
+			Where("NOT EXISTS (SELECT 1 FROM source_links o WHERE o.launch_id = l.launch_id AND o.to_url = l.to_url AND o.from_source_id = ?)", to),
+		pgSql.
+			Update("source_links").
+			Set("to_source_id", to).
+			Where(squirrel.Eq{"to_source_id": from}),
+		// История дубля продолжает историю источника
+		pgSql.
+			Update(sourceVersionsTbl).
+			Set(sourceIDCol, to).
+			Set(versionCol, squirrel.Expr(versionCol+" + (SELECT COALESCE(MAX(version), 0) FROM source_versions WHERE source_id = ?)", to)).
+			Where(squirrel.Eq{sourceIDCol: from}),
+		pgSql.
+			Delete(sourcesTbl).
+			Where(squirrel.Eq{idCol: from}),
+	}
+}
+
+// Rekey заполняет ключ адреса не более limit источников, сохраненных до его появления, и возвращает их количество.
+// Источник, ключ которого уже занят, объединяется с владельцем ключа
+func (s *Storage) Rekey(ctx context.Context, limit int) (int, error) {
+	var res []key
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	sql, args := pgSql.
+		Select(urlCol, idCol).
+		From(sourcesTbl).
+		Where(squirrel.Eq{urlKeyCol: nil}).
+		OrderBy(idCol).
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE SKIP LOCKED").
+		MustSql()
+
+	err = tx.SelectContext(ctx, &res, sql, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, pg := range res {
+		var ownerIDs []int64
+
+		sql, args := pgSql.
+			Select(idCol).
+			From(sourcesTbl).
+			Where(squirrel.Eq{urlKeyCol: urlKey(pg.URL)}).
+			Suffix("FOR UPDATE").
+			MustSql()
+
+		if err := tx.SelectContext(ctx, &ownerIDs, sql, args...); err != nil {
+			return 0, fmt.Errorf("failed to get owner of source [%d] key: %w", pg.ID, err)
+		}
+
+		if len(ownerIDs) == 0 {
+			sql, args = pgSql.
+				Update(sourcesTbl).
+				Set(urlKeyCol, urlKey(pg.URL)).
+				Where(squirrel.Eq{idCol: pg.ID}).
+				MustSql()
+
+			if _, err := tx.ExecContext(ctx, sql, args...); err != nil {
+				return 0, fmt.Errorf("failed to set key of source [%d]: %w", pg.ID, err)
+			}
+
+			continue
+		}
+
+		for _, q := range mergeQueries(pg.ID, ownerIDs[0]) {
+			sql, args, err := q.ToSql()
+			if err != nil {
+				return 0, err
+			}
+
+			if _, err := tx.ExecContext(ctx, sql, args...); err != nil {
+				return 0, fmt.Errorf("failed to merge source [%d] into [%d]: %w", pg.ID, ownerIDs[0], err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return len(res), nil
+}
@@ -0,0 +1,197 @@
+package sources
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/K1flar/crawlers/internal/models/source"
+	"github.com/K1flar/crawlers/internal/storage"
+	"github.com/jmoiron/sqlx"
+)
+
+func newMock(t *testing.T) (*Storage, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewStorage(sqlx.NewDb(db, "postgres")), mock
+}
+
+func TestUpdateKeysResultByRequestedURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		storedURL string
+	}{
+		{"normalized row", "http://example.com/a"},
+		{"row stored before normalization", "HTTP://Example.com:80/a/?utm_source=x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, mock := newMock(t)
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(`SELECT title, status, content_hash FROM sources WHERE id = \$1 FOR UPDATE`).
+				WithArgs(int64(7)).
+				WillReturnRows(sqlmock.NewRows([]string{"title", "status", "content_hash"}).AddRow("t", "available", "h"))
+			mock.ExpectQuery(`UPDATE sources SET .* returning url, id`).
+				WillReturnRows(sqlmock.NewRows([]string{"url", "id"}).AddRow(tt.storedURL, 7))
+			mock.ExpectCommit()
+
+			out, err := s.Update(context.Background(), []storage.ToUpdateSource{{
+				ID:          7,
+				URL:         "http://example.com/a",
+				Title:       "t",
+				Status:      source.StatusAvailable,
+				UpdatedAt:   time.Now(),
+				ContentHash: "h",
+			}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if out["http://example.com/a"] != 7 || len(out) != 1 {
+				t.Fatalf("unexpected result %v", out)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestGetByURLsMatchesSchemesAndLegacyRows(t *testing.T) {
+	s, mock := newMock(t)
+
+	rows := sqlmock.NewRows(readColumns)
+	rows.AddRow(3, "old", "http://example.com/a", "available", time.Now(), time.Now(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	rows.AddRow(5, "dup", "https://example.com/a", "available", time.Now(), time.Now(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	rows.AddRow(8, "legacy", "HTTP://Example.com/b/", "available", time.Now(), time.Now(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	mock.ExpectQuery(`SELECT .* FROM sources WHERE \(url_key IN .* OR url IN .*\) ORDER BY id`).WillReturnRows(rows)
+
+	out, err := s.GetByURLs(context.Background(), []string{"https://example.com/a", "http://example.com/b", "http://example.com/c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]int64{
+		"https://example.com/a": 3,
+		"http://example.com/b":  8,
+	}
+
+	if len(out) != len(want) {
+		t.Fatalf("unexpected result %v", out)
+	}
+
+	for url, id := range want {
+		if out[url].ID != id {
+			t.Fatalf("source for %s: got %d, want %d", url, out[url].ID, id)
+		}
+	}
+}
+
+func TestCreateStoresSchemesAsOneSource(t *testing.T) {
+	s, mock := newMock(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO sources`).
+		WillReturnRows(sqlmock.NewRows([]string{"url", "id"}).AddRow("https://example.com/a", 11))
+	mock.ExpectExec(`INSERT INTO source_versions`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	out, err := s.Create(context.Background(), []storage.ToCreateSource{
+		{URL: "https://example.com/a", Status: source.StatusAvailable, CreatedAt: time.Now()},
+		{URL: "http://Example.com/a/", Status: source.StatusAvailable, CreatedAt: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out["https://example.com/a"] != 11 || out["http://Example.com/a/"] != 11 {
+		t.Fatalf("unexpected result %v", out)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCreateTakesSourceSavedConcurrently(t *testing.T) {
+	s, mock := newMock(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO sources .* ON CONFLICT DO NOTHING returning url, id`).
+		WillReturnRows(sqlmock.NewRows([]string{"url", "id"}).AddRow("http://example.com/new", 12))
+	mock.ExpectExec(`INSERT INTO source_versions`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT url, id FROM sources WHERE \(url_key IN \(\$1\) OR url IN \(\$2\)\) ORDER BY id`).
+		WithArgs("example.com/a", "http://example.com/a").
+		WillReturnRows(sqlmock.NewRows([]string{"url", "id"}).AddRow("http://example.com/a", 7))
+	mock.ExpectCommit()
+
+	out, err := s.Create(context.Background(), []storage.ToCreateSource{
+		{URL: "http://example.com/a", Status: source.StatusAvailable, CreatedAt: time.Now()},
+		{URL: "http://example.com/new", Status: source.StatusAvailable, CreatedAt: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out["http://example.com/a"] != 7 || out["http://example.com/new"] != 12 || len(out) != 2 {
+		t.Fatalf("unexpected result %v", out)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRekeyFillsKeysAndMergesDuplicates(t *testing.T) {
+	s, mock := newMock(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT url, id FROM sources WHERE url_key IS NULL ORDER BY id LIMIT 10 FOR UPDATE SKIP LOCKED`).
+		WillReturnRows(sqlmock.NewRows([]string{"url", "id"}).
+			AddRow("HTTP://Example.com:80/a/?utm_source=x", 3).
+			AddRow("https://example.com/b", 4))
+
+	// У первого источника ключ свободен
+	mock.ExpectQuery(`SELECT id FROM sources WHERE url_key = \$1 FOR UPDATE`).
+		WithArgs("example.com/a").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectExec(`UPDATE sources SET url_key = \$1 WHERE id = \$2`).
+		WithArgs("example.com/a", int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Второй - дубль источника, сохраненного после нормализации
+	mock.ExpectQuery(`SELECT id FROM sources WHERE url_key = \$1 FOR UPDATE`).
+		WithArgs("example.com/b").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+	for _, table := range []string{"tasks_x_sources t", "tasks_x_sources", "source_contents c", "source_aliases a", "source_links l", "source_links", "source_versions"} {
+		mock.ExpectExec(`UPDATE ` + table + ` SET`).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectExec(`DELETE FROM sources WHERE id = \$1`).
+		WithArgs(int64(4)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	count, err := s.Rekey(context.Background(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 2 {
+		t.Fatalf("got %d rekeyed sources, want 2", count)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
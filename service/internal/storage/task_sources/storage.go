@@ -29,6 +29,10 @@ const (
 	sourceIDCol       = "source_id"
 	parentSourceIDCol = "parent_source_id"
 	weightCol         = "weight"
+	scorerCol         = "scorer"
+	pageRankCol       = "pagerank"
+	hubScoreCol       = "hub_score"
+	authorityScoreCol = "authority_score"
 )
 
 type sourcePG struct {
@@ -67,10 +71,10 @@ func (s *Storage) Create(ctx context.Context, params []storage.ToCreateTaskSourc
 
 	q := pgSql.
 		Insert(tasksSourcesTbl).
-		Columns(taskIDCol, launchIDCol, sourceIDCol, parentSourceIDCol, weightCol)
+		Columns(taskIDCol, launchIDCol, sourceIDCol, parentSourceIDCol, weightCol, scorerCol, pageRankCol, hubScoreCol, authorityScoreCol)
 
 	for _, p := range params {
-		q = q.Values(p.TaskID, p.LaunchID, p.SourceID, p.ParentSourceID, p.Weight)
+		q = q.Values(p.TaskID, p.LaunchID, p.SourceID, p.ParentSourceID, p.Weight, p.Scorer, p.Graph.PageRank, p.Graph.Hub, p.Graph.Authority)
 	}
 
 	sql, args := q.MustSql()
@@ -2,6 +2,9 @@ package tasks
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -22,6 +25,9 @@ type Storage struct {
 
 var pgSql = squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 
+// notDeleted - условие для задач, которые не удалены пользователем
+var notDeleted = squirrel.Eq{deletedAtCol: nil}
+
 const (
 	tasksTbl = "tasks"
 
@@ -31,10 +37,28 @@ const (
 	createdAtCol              = "created_at"
 	updatedAtCol              = "updated_at"
 	processedAtCol            = "processed_at"
+	nextRunAtCol              = "next_run_at"
 	depthLevelCol             = "depth_level"
 	minWeightCol              = "min_weight"
 	maxSourcesCol             = "max_sources"
 	maxNeighboursForSourceCol = "max_neighbours_for_source"
+	hostRequestsPerSecondCol  = "host_requests_per_second"
+	hostBurstCol              = "host_burst"
+	hostMaxInFlightCol        = "host_max_in_flight"
+	scorerCol                 = "scorer"
+	scorerParamsCol           = "scorer_params"
+	languageCol               = "language"
+	duplicateDistanceCol      = "duplicate_distance"
+	scopeRulesCol             = "scope_rules"
+	scheduleCol               = "schedule"
+	configVersionCol          = "config_version"
+	deletedAtCol              = "deleted_at"
+
+	taskConfigsTbl = "task_configs"
+
+	taskIDCol  = "task_id"
+	versionCol = "version"
+	configCol  = "config"
 
 	countSourcesCol = "count_sources"
 )
@@ -46,10 +70,21 @@ var readColumns = []string{
 	createdAtCol,
 	updatedAtCol,
 	processedAtCol,
+	nextRunAtCol,
 	depthLevelCol,
 	minWeightCol,
 	maxSourcesCol,
 	maxNeighboursForSourceCol,
+	hostRequestsPerSecondCol,
+	hostBurstCol,
+	hostMaxInFlightCol,
+	scorerCol,
+	scorerParamsCol,
+	languageCol,
+	duplicateDistanceCol,
+	scopeRulesCol,
+	scheduleCol,
+	configVersionCol,
 }
 
 type taskPG struct {
@@ -59,10 +94,58 @@ type taskPG struct {
 	CreatedAt              time.Time  `db:"created_at"`
 	UpdatedAt              time.Time  `db:"updated_at"`
 	ProcessedAt            *time.Time `db:"processed_at"`
+	NextRunAt              *time.Time `db:"next_run_at"`
 	DepthLevel             int        `db:"depth_level"`
 	MinWeight              float64    `db:"min_weight"`
 	MaxSources             int64      `db:"max_sources"`
 	MaxNeighboursForSource int64      `db:"max_neighbours_for_source"`
+	HostRequestsPerSecond  float64    `db:"host_requests_per_second"`
+	HostBurst              int64      `db:"host_burst"`
+	HostMaxInFlight        int64      `db:"host_max_in_flight"`
+	Scorer                 string     `db:"scorer"`
+	ScorerParams           []byte     `db:"scorer_params"`
+	Language               string     `db:"language"`
+	DuplicateDistance      int        `db:"duplicate_distance"`
+	ScopeRules             []byte     `db:"scope_rules"`
+	Schedule               string     `db:"schedule"`
+	ConfigVersion          int64      `db:"config_version"`
+}
+
+type scorerParamsPG struct {
+	K1          *float64 `json:"k1,omitempty"`
+	B           *float64 `json:"b,omitempty"`
+	TitleWeight *float64 `json:"titleWeight,omitempty"`
+	BodyWeight  *float64 `json:"bodyWeight,omitempty"`
+
+	PageRankWeight  *float64 `json:"pageRankWeight,omitempty"`
+	AuthorityWeight *float64 `json:"authorityWeight,omitempty"`
+}
+
+type scopeRulesPG struct {
+	SameDomain        bool     `json:"sameDomain,omitempty"`
+	AllowHosts        []string `json:"allowHosts,omitempty"`
+	DenyHosts         []string `json:"denyHosts,omitempty"`
+	PathPrefixes      []string `json:"pathPrefixes,omitempty"`
+	Include           []string `json:"include,omitempty"`
+	Exclude           []string `json:"exclude,omitempty"`
+	BlockedExtensions []string `json:"blockedExtensions,omitempty"`
+}
+
+// configPG - версия параметров обхода в task_configs
+type configPG struct {
+	DepthLevel             int            `json:"depthLevel"`
+	MinWeight              float64        `json:"minWeight"`
+	MaxSources             int64          `json:"maxSources"`
+	MaxNeighboursForSource int64          `json:"maxNeighboursForSource"`
+	HostRequestsPerSecond  float64        `json:"hostRequestsPerSecond"`
+	HostBurst              int64          `json:"hostBurst"`
+	HostMaxInFlight        int64          `json:"hostMaxInFlight"`
+	Scorer                 string         `json:"scorer"`
+	ScorerParams           scorerParamsPG `json:"scorerParams"`
+	Language               string         `json:"language"`
+	DuplicateDistance      int            `json:"duplicateDistance"`
+	ScopeRules             scopeRulesPG   `json:"scopeRules"`
+	Schedule               string         `json:"schedule"`
 }
 
 type taskForListPG struct {
@@ -77,17 +160,21 @@ func NewStorage(db *sqlx.DB) *Storage {
 }
 
 func (s *Storage) GetByID(ctx context.Context, id int64) (task.Task, error) {
-	var task taskPG
+	var pg taskPG
 
-	sql, args := pgSql.
+	query, args := pgSql.
 		Select(readColumns...).
 		From(tasksTbl).
 		Where(squirrel.Eq{idCol: id}).
+		Where(notDeleted).
 		MustSql()
 
-	err := s.db.GetContext(ctx, &task, sql, args...)
+	err := s.db.GetContext(ctx, &pg, query, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return task.Task{}, business_errors.EntityNotFound
+	}
 
-	return mapFromPG(task), err
+	return mapFromPG(pg), err
 }
 
 func (s *Storage) GetForList(ctx context.Context, filter storage.FilterTaskForList) ([]task.ForList, error) {
@@ -115,6 +202,7 @@ func (s *Storage) GetForList(ctx context.Context, filter storage.FilterTaskForLi
 		From("tasks t").
 		LeftJoin("last_launches ll ON ll.task_id = t.id").
 		LeftJoin("tasks_x_sources txs ON txs.task_id = t.id AND txs.launch_id = ll.last_launch_id").
+		Where("t.deleted_at IS NULL").
 		GroupBy("t.id")
 
 	if filter.Status != nil {
@@ -157,6 +245,7 @@ func (s *Storage) GetCount(ctx context.Context) (int64, error) {
 	sql, args := pgSql.
 		Select("count(*)").
 		From(tasksTbl).
+		Where(notDeleted).
 		MustSql()
 
 	err := s.db.QueryRowContext(ctx, sql, args...).Scan(&count)
@@ -171,6 +260,7 @@ func (s *Storage) FindInStatuses(ctx context.Context, statuses []task.Status) ([
 		Select(readColumns...).
 		From(tasksTbl).
 		Where(squirrel.Eq{statusCol: statuses}).
+		Where(notDeleted).
 		MustSql()
 
 	err := s.db.SelectContext(ctx, &tasks, sql, args...)
@@ -178,10 +268,18 @@ func (s *Storage) FindInStatuses(ctx context.Context, statuses []task.Status) ([
 	return mapFromPgMany(tasks), err
 }
 
+// Create создает задачу вместе с первой версией параметров обхода
 func (s *Storage) Create(ctx context.Context, params storage.ToCreateTask) (int64, error) {
 	var id int64
 
 	now := time.Now()
+	cfg := params.Config
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
 	sql, args := pgSql.
 		Insert(tasksTbl).
@@ -194,23 +292,55 @@ func (s *Storage) Create(ctx context.Context, params storage.ToCreateTask) (int6
 			minWeightCol,
 			maxSourcesCol,
 			maxNeighboursForSourceCol,
+			hostRequestsPerSecondCol,
+			hostBurstCol,
+			hostMaxInFlightCol,
+			scorerCol,
+			scorerParamsCol,
+			languageCol,
+			duplicateDistanceCol,
+			scopeRulesCol,
+			scheduleCol,
+			configVersionCol,
+			nextRunAtCol,
 		).
 		Values(
 			params.Query,
 			task.StatusCreated,
 			now,
 			now,
-			params.DepthLevel,
-			params.MinWeight,
-			params.MaxSources,
-			params.MaxNeighboursForSource,
+			cfg.DepthLevel,
+			cfg.MinWeight,
+			cfg.MaxSources,
+			cfg.MaxNeighboursForSource,
+			cfg.HostRequestsPerSecond,
+			cfg.HostBurst,
+			cfg.HostMaxInFlight,
+			cfg.Scorer,
+			marshal(mapScorerParamsToPG(cfg.ScorerParams)),
+			cfg.Language,
+			cfg.DuplicateDistance,
+			marshal(mapScopeRulesToPG(cfg.ScopeRules)),
+			cfg.Schedule,
+			1,
+			params.NextRunAt,
 		).
 		Suffix(returning(idCol)).
 		MustSql()
 
-	err := s.db.GetContext(ctx, &id, sql, args...)
+	if err := tx.GetContext(ctx, &id, sql, args...); err != nil {
+		return 0, err
+	}
+
+	if err := createConfigVersion(ctx, tx, id, 1, cfg, now); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
 
-	return id, err
+	return id, nil
 }
 
 func (s *Storage) SetStatus(ctx context.Context, id int64, status task.Status) error {
@@ -219,6 +349,7 @@ func (s *Storage) SetStatus(ctx context.Context, id int64, status task.Status) e
 		Set(statusCol, status).
 		Set(updatedAtCol, time.Now()).
 		Where(squirrel.Eq{idCol: id}).
+		Where(notDeleted).
 		MustSql()
 
 	res, err := s.db.ExecContext(ctx, sql, args...)
@@ -244,6 +375,7 @@ func (s *Storage) Process(ctx context.Context, id int64) error {
 		Set(statusCol, task.StatusInPocessing).
 		Set(processedAtCol, time.Now()).
 		Where(squirrel.Eq{idCol: id}).
+		Where(notDeleted).
 		MustSql()
 
 	res, err := s.db.ExecContext(ctx, sql, args...)
@@ -263,15 +395,106 @@ func (s *Storage) Process(ctx context.Context, id int64) error {
 	return nil
 }
 
-func (s *Storage) Update(ctx context.Context, params storage.ToUpdateTask) error {
+// FinishProcessing переводит задачу из обработки в status. Возвращает false, если задачу
+// за время обработки перевели в другой статус, например остановили
+func (s *Storage) FinishProcessing(ctx context.Context, id int64, status task.Status) (bool, error) {
 	sql, args := pgSql.
 		Update(tasksTbl).
+		Set(statusCol, status).
 		Set(updatedAtCol, time.Now()).
-		Set(depthLevelCol, squirrel.Expr("coalesce(?, depth_level)", params.DepthLevel)).
-		Set(minWeightCol, squirrel.Expr("coalesce(?, min_weight)", params.MinWeight)).
-		Set(maxSourcesCol, squirrel.Expr("coalesce(?, max_sources)", params.MaxSources)).
-		Set(maxNeighboursForSourceCol, squirrel.Expr("coalesce(?, max_neighbours_for_source)", params.MaxNeighboursForSource)).
-		Where(squirrel.Eq{idCol: params.ID}).
+		Where(squirrel.Eq{idCol: id, statusCol: task.StatusInPocessing}).
+		MustSql()
+
+	res, err := s.db.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows != 0, nil
+}
+
+// ClaimDue забирает активную задачу с наступившим временем запуска, которую не обрабатывает
+// другой планировщик, и сразу сохраняет время следующего запуска, посчитанное next.
+// Задача ставится в очередь уже после фиксации транзакции, NextRunAt возвращенной задачи -
+// наступившее время запуска. Возвращает false, если таких задач нет
+func (s *Storage) ClaimDue(ctx context.Context, now time.Time, next func(t task.Task) time.Time) (task.Task, bool, error) {
+	var pg taskPG
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return task.Task{}, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query, args := pgSql.
+		Select(readColumns...).
+		From(tasksTbl).
+		Where(squirrel.Eq{statusCol: task.StatusActive}).
+		Where(squirrel.LtOrEq{nextRunAtCol: now}).
+		Where(notDeleted).
+		OrderBy(nextRunAtCol).
+		Limit(1).
+		Suffix("FOR UPDATE SKIP LOCKED").
+		MustSql()
+
+	err = tx.GetContext(ctx, &pg, query, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return task.Task{}, false, nil
+	}
+	if err != nil {
+		return task.Task{}, false, err
+	}
+
+	t := mapFromPG(pg)
+	nextRunAt := next(t)
+
+	query, args = pgSql.
+		Update(tasksTbl).
+		Set(nextRunAtCol, nextRunAt).
+		Where(squirrel.Eq{idCol: pg.ID}).
+		MustSql()
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return task.Task{}, false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return task.Task{}, false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return t, true, nil
+}
+
+// ReleaseDue возвращает задаче время запуска dueAt, если ее не удалось поставить в очередь после ClaimDue.
+// Время не меняется, если с момента ClaimDue расписание задачи успели изменить
+func (s *Storage) ReleaseDue(ctx context.Context, id int64, claimedNextRunAt, dueAt time.Time) error {
+	query, args := pgSql.
+		Update(tasksTbl).
+		Set(nextRunAtCol, dueAt).
+		Where(squirrel.Eq{idCol: id, nextRunAtCol: claimedNextRunAt}).
+		MustSql()
+
+	_, err := s.db.ExecContext(ctx, query, args...)
+
+	return err
+}
+
+// Delete помечает задачу удаленной и останавливает ее, чтобы прервать идущий обход
+func (s *Storage) Delete(ctx context.Context, id int64) error {
+	now := time.Now()
+
+	sql, args := pgSql.
+		Update(tasksTbl).
+		Set(statusCol, task.StatusStopped).
+		Set(updatedAtCol, now).
+		Set(deletedAtCol, now).
+		Where(squirrel.Eq{idCol: id}).
+		Where(notDeleted).
 		MustSql()
 
 	res, err := s.db.ExecContext(ctx, sql, args...)
@@ -291,21 +514,220 @@ func (s *Storage) Update(ctx context.Context, params storage.ToUpdateTask) error
 	return nil
 }
 
+// PurgeDeleted окончательно удаляет задачи, удаленные раньше deletedBefore, вместе с запусками
+func (s *Storage) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	sql, args := pgSql.
+		Delete(tasksTbl).
+		Where(squirrel.Lt{deletedAtCol: deletedBefore}).
+		MustSql()
+
+	res, err := s.db.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// Update блокирует задачу до конца транзакции и сохраняет параметры, которые apply построил по ее текущему состоянию.
+// Ошибка apply возвращается как есть, задача при этом не меняется
+func (s *Storage) Update(ctx context.Context, id int64, apply func(t task.Task) (storage.ToUpdateTask, error)) error {
+	var (
+		pg      taskPG
+		version int64
+	)
+
+	now := time.Now()
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query, args := pgSql.
+		Select(readColumns...).
+		From(tasksTbl).
+		Where(squirrel.Eq{idCol: id}).
+		Where(notDeleted).
+		Suffix("FOR UPDATE").
+		MustSql()
+
+	err = tx.GetContext(ctx, &pg, query, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return business_errors.EntityNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	params, err := apply(mapFromPG(pg))
+	if err != nil {
+		return err
+	}
+
+	cfg := params.Config
+
+	q := pgSql.
+		Update(tasksTbl).
+		Set(updatedAtCol, now).
+		Set(depthLevelCol, cfg.DepthLevel).
+		Set(minWeightCol, cfg.MinWeight).
+		Set(maxSourcesCol, cfg.MaxSources).
+		Set(maxNeighboursForSourceCol, cfg.MaxNeighboursForSource).
+		Set(hostRequestsPerSecondCol, cfg.HostRequestsPerSecond).
+		Set(hostBurstCol, cfg.HostBurst).
+		Set(hostMaxInFlightCol, cfg.HostMaxInFlight).
+		Set(scorerCol, cfg.Scorer).
+		Set(scorerParamsCol, marshal(mapScorerParamsToPG(cfg.ScorerParams))).
+		Set(languageCol, cfg.Language).
+		Set(duplicateDistanceCol, cfg.DuplicateDistance).
+		Set(scopeRulesCol, marshal(mapScopeRulesToPG(cfg.ScopeRules))).
+		Set(scheduleCol, cfg.Schedule).
+		Set(configVersionCol, squirrel.Expr(configVersionCol+" + 1"))
+
+	if params.NextRunAt != nil {
+		q = q.Set(nextRunAtCol, *params.NextRunAt)
+	}
+
+	query, args = q.
+		Where(squirrel.Eq{idCol: id}).
+		Suffix(returning(configVersionCol)).
+		MustSql()
+
+	if err := tx.GetContext(ctx, &version, query, args...); err != nil {
+		return err
+	}
+
+	if err := createConfigVersion(ctx, tx, id, version, cfg, now); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func createConfigVersion(ctx context.Context, tx *sqlx.Tx, taskID, version int64, cfg task.Config, createdAt time.Time) error {
+	query, args := pgSql.
+		Insert(taskConfigsTbl).
+		Columns(taskIDCol, versionCol, configCol, createdAtCol).
+		Values(taskID, version, marshal(configPG{
+			DepthLevel:             cfg.DepthLevel,
+			MinWeight:              cfg.MinWeight,
+			MaxSources:             cfg.MaxSources,
+			MaxNeighboursForSource: cfg.MaxNeighboursForSource,
+			HostRequestsPerSecond:  cfg.HostRequestsPerSecond,
+			HostBurst:              cfg.HostBurst,
+			HostMaxInFlight:        cfg.HostMaxInFlight,
+			Scorer:                 cfg.Scorer,
+			ScorerParams:           mapScorerParamsToPG(cfg.ScorerParams),
+			Language:               cfg.Language,
+			DuplicateDistance:      cfg.DuplicateDistance,
+			ScopeRules:             mapScopeRulesToPG(cfg.ScopeRules),
+			Schedule:               cfg.Schedule,
+		}), createdAt).
+		MustSql()
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to create task config version: %w", err)
+	}
+
+	return nil
+}
+
 func mapFromPG(pg taskPG) task.Task {
 	return task.Task{
-		ID:                     pg.ID,
-		Query:                  pg.Query,
-		Status:                 task.Status(pg.Status),
-		CreatedAt:              pg.CreatedAt,
-		UpdatedAt:              pg.UpdatedAt,
-		ProcessedAt:            pg.ProcessedAt,
-		DepthLevel:             pg.DepthLevel,
-		MinWeight:              pg.MinWeight,
-		MaxSources:             pg.MaxSources,
-		MaxNeighboursForSource: pg.MaxNeighboursForSource,
+		ID:            pg.ID,
+		Query:         pg.Query,
+		Status:        task.Status(pg.Status),
+		CreatedAt:     pg.CreatedAt,
+		UpdatedAt:     pg.UpdatedAt,
+		ProcessedAt:   pg.ProcessedAt,
+		NextRunAt:     pg.NextRunAt,
+		ConfigVersion: pg.ConfigVersion,
+		Config: task.Config{
+			DepthLevel:             pg.DepthLevel,
+			MinWeight:              pg.MinWeight,
+			MaxSources:             pg.MaxSources,
+			MaxNeighboursForSource: pg.MaxNeighboursForSource,
+			HostRequestsPerSecond:  pg.HostRequestsPerSecond,
+			HostBurst:              pg.HostBurst,
+			HostMaxInFlight:        pg.HostMaxInFlight,
+			Scorer:                 pg.Scorer,
+			ScorerParams:           scorerParamsFromPG(pg.ScorerParams),
+			Language:               pg.Language,
+			DuplicateDistance:      pg.DuplicateDistance,
+			ScopeRules:             scopeRulesFromPG(pg.ScopeRules),
+			Schedule:               pg.Schedule,
+		},
+	}
+}
+
+func scorerParamsFromPG(raw []byte) task.ScorerParams {
+	var pg scorerParamsPG
+
+	// Некорректные параметры заменяются значениями по умолчанию
+	_ = json.Unmarshal(raw, &pg)
+
+	return task.ScorerParams{
+		K1:              pg.K1,
+		B:               pg.B,
+		TitleWeight:     pg.TitleWeight,
+		BodyWeight:      pg.BodyWeight,
+		PageRankWeight:  pg.PageRankWeight,
+		AuthorityWeight: pg.AuthorityWeight,
 	}
 }
 
+func mapScorerParamsToPG(params task.ScorerParams) scorerParamsPG {
+	return scorerParamsPG{
+		K1:              params.K1,
+		B:               params.B,
+		TitleWeight:     params.TitleWeight,
+		BodyWeight:      params.BodyWeight,
+		PageRankWeight:  params.PageRankWeight,
+		AuthorityWeight: params.AuthorityWeight,
+	}
+}
+
+func scopeRulesFromPG(raw []byte) task.ScopeRules {
+	var pg scopeRulesPG
+
+	_ = json.Unmarshal(raw, &pg)
+
+	return task.ScopeRules{
+		SameDomain:        pg.SameDomain,
+		AllowHosts:        pg.AllowHosts,
+		DenyHosts:         pg.DenyHosts,
+		PathPrefixes:      pg.PathPrefixes,
+		Include:           pg.Include,
+		Exclude:           pg.Exclude,
+		BlockedExtensions: pg.BlockedExtensions,
+	}
+}
+
+func mapScopeRulesToPG(rules task.ScopeRules) scopeRulesPG {
+	return scopeRulesPG{
+		SameDomain:        rules.SameDomain,
+		AllowHosts:        rules.AllowHosts,
+		DenyHosts:         rules.DenyHosts,
+		PathPrefixes:      rules.PathPrefixes,
+		Include:           rules.Include,
+		Exclude:           rules.Exclude,
+		BlockedExtensions: rules.BlockedExtensions,
+	}
+}
+
+// marshal сериализует значение для колонки JSONB
+func marshal(v any) string {
+	b, _ := json.Marshal(v)
+
+	return string(b)
+}
+
 func mapFromPgMany(pgs []taskPG) []task.Task {
 	return lo.Map(pgs, func(pg taskPG, _ int) task.Task {
 		return mapFromPG(pg)
@@ -0,0 +1,120 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/K1flar/crawlers/internal/business_errors"
+	"github.com/K1flar/crawlers/internal/models/task"
+	"github.com/K1flar/crawlers/internal/storage"
+	"github.com/jmoiron/sqlx"
+)
+
+func newMock(t *testing.T) (*Storage, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewStorage(sqlx.NewDb(db, "postgres")), mock
+}
+
+func taskRows(id int64, duplicateDistance int, schedule string, configVersion int64) *sqlmock.Rows {
+	now := time.Now()
+
+	return sqlmock.NewRows(readColumns).AddRow(
+		id, "query", string(task.StatusActive), now, now, nil, now,
+		3, 0.0, int64(20), int64(20), 1.0, int64(1), int64(2),
+		"bm25", []byte("{}"), "russian", duplicateDistance, []byte("{}"), schedule, configVersion,
+	)
+}
+
+const lockTaskQuery = `SELECT id, query, .*, config_version FROM tasks WHERE id = \$1 AND deleted_at IS NULL FOR UPDATE`
+
+func TestUpdateAppliesChangesToLockedTask(t *testing.T) {
+	s, mock := newMock(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(lockTaskQuery).
+		WithArgs(int64(7)).
+		WillReturnRows(taskRows(7, 3, "12h", 2))
+	mock.ExpectQuery(`UPDATE tasks SET .* config_version = config_version \+ 1 WHERE id = \$\d+ returning config_version`).
+		WillReturnRows(sqlmock.NewRows([]string{configVersionCol}).AddRow(int64(3)))
+	mock.ExpectExec(`INSERT INTO task_configs \(task_id,version,config,created_at\)`).
+		WithArgs(int64(7), int64(3), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	var locked task.Task
+
+	err := s.Update(context.Background(), 7, func(t task.Task) (storage.ToUpdateTask, error) {
+		locked = t
+
+		cfg := t.Config
+		cfg.DuplicateDistance = 5
+
+		return storage.ToUpdateTask{Config: cfg}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if locked.ID != 7 || locked.DuplicateDistance != 3 || locked.Schedule != "12h" || locked.ConfigVersion != 2 {
+		t.Fatalf("apply got unexpected task %+v", locked)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUpdateKeepsTaskOnApplyError(t *testing.T) {
+	s, mock := newMock(t)
+
+	applyErr := errors.New("invalid config")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(lockTaskQuery).
+		WithArgs(int64(7)).
+		WillReturnRows(taskRows(7, 3, "", 1))
+	mock.ExpectRollback()
+
+	err := s.Update(context.Background(), 7, func(task.Task) (storage.ToUpdateTask, error) {
+		return storage.ToUpdateTask{}, applyErr
+	})
+	if !errors.Is(err, applyErr) {
+		t.Fatalf("expected apply error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUpdateNotFound(t *testing.T) {
+	s, mock := newMock(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(lockTaskQuery).
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows(readColumns))
+	mock.ExpectRollback()
+
+	err := s.Update(context.Background(), 7, func(task.Task) (storage.ToUpdateTask, error) {
+		t.Fatal("apply called for missing task")
+		return storage.ToUpdateTask{}, nil
+	})
+	if !errors.Is(err, business_errors.EntityNotFound) {
+		t.Fatalf("expected EntityNotFound, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,93 @@
+package compare_launches
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/K1flar/crawlers/internal/models/launch"
+	"github.com/K1flar/crawlers/internal/models/source"
+	"github.com/K1flar/crawlers/internal/storage"
+	"github.com/samber/lo"
+)
+
+type Story struct {
+	launches storage.Launches
+	sources  storage.Sources
+}
+
+func NewStory(
+	launches storage.Launches,
+	sources storage.Sources,
+) *Story {
+	return &Story{launches, sources}
+}
+
+func (s *Story) Compare(ctx context.Context, taskID, fromNumber, toNumber int64) (source.Comparison, error) {
+	from, fromSources, err := s.launchSources(ctx, taskID, fromNumber)
+	if err != nil {
+		return source.Comparison{}, err
+	}
+
+	to, toSources, err := s.launchSources(ctx, taskID, toNumber)
+	if err != nil {
+		return source.Comparison{}, err
+	}
+
+	comparison := source.Comparison{
+		From:    from,
+		To:      to,
+		Added:   make([]source.ForTask, 0),
+		Removed: make([]source.ForTask, 0),
+		Kept:    make([]source.Kept, 0),
+	}
+
+	for id, src := range toSources {
+		prev, ok := fromSources[id]
+		if !ok {
+			comparison.Added = append(comparison.Added, src)
+			continue
+		}
+
+		comparison.Kept = append(comparison.Kept, source.Kept{
+			ForTask:      src,
+			PrevWeight:   prev.Weight,
+			PrevParentID: prev.ParentID,
+		})
+	}
+
+	for id, src := range fromSources {
+		if _, ok := toSources[id]; !ok {
+			comparison.Removed = append(comparison.Removed, src)
+		}
+	}
+
+	byWeight := func(a, b source.ForTask) int {
+		return cmp.Or(cmp.Compare(b.Weight, a.Weight), cmp.Compare(a.ID, b.ID))
+	}
+
+	slices.SortFunc(comparison.Added, byWeight)
+	slices.SortFunc(comparison.Removed, byWeight)
+	slices.SortFunc(comparison.Kept, func(a, b source.Kept) int {
+		return byWeight(a.ForTask, b.ForTask)
+	})
+
+	return comparison, nil
+}
+
+func (s *Story) launchSources(ctx context.Context, taskID, number int64) (launch.Launch, map[int64]source.ForTask, error) {
+	l, err := s.launches.GetByNumber(ctx, taskID, number)
+	if err != nil {
+		return launch.Launch{}, nil, fmt.Errorf("failed to get launch [%d] of task [%d]: %w", number, taskID, err)
+	}
+
+	sources, err := s.sources.GetByLaunchID(ctx, l.ID)
+	if err != nil {
+		return launch.Launch{}, nil, fmt.Errorf("failed to get sources of launch [%d]: %w", l.ID, err)
+	}
+
+	return l, lo.KeyBy(sources, func(s source.ForTask) int64 {
+		return s.ID
+	}), nil
+}
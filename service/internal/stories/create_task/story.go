@@ -5,21 +5,18 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"time"
 
 	"github.com/K1flar/crawlers/internal/business_errors"
 	"github.com/K1flar/crawlers/internal/message_broker"
 	"github.com/K1flar/crawlers/internal/message_broker/messages"
+	"github.com/K1flar/crawlers/internal/services/task_config"
 	"github.com/K1flar/crawlers/internal/storage"
 )
 
 const (
 	maxCountWords = 10
 	maxLenWord    = 20
-
-	defaultDepthLevel             = 3
-	defaultMinWeight              = 0
-	defaultMaxSources             = 20
-	defaultMaxNeighboursForSource = 20
 )
 
 type Story struct {
@@ -36,17 +33,24 @@ func NewStory(
 	return &Story{log, tasks, producer}
 }
 
-func (s *Story) Create(ctx context.Context, query string) (int64, error) {
+// Create создает задачу и ставит ее в очередь на первый запуск.
+// Незаданные параметры обхода берутся по умолчанию
+func (s *Story) Create(ctx context.Context, query string, patch task_config.Patch) (int64, error) {
 	if err := s.validateQuery(query); err != nil {
 		return 0, err
 	}
 
+	cfg := patch.Apply(task_config.Default())
+
+	if err := task_config.Validate(cfg); err != nil {
+		return 0, err
+	}
+
+	// Первый запуск ставится в очередь сразу, следующий - по расписанию
 	id, err := s.tasks.Create(ctx, storage.ToCreateTask{
-		Query:                  query,
-		DepthLevel:             defaultDepthLevel,
-		MinWeight:              defaultMinWeight,
-		MaxSources:             defaultMaxSources,
-		MaxNeighboursForSource: defaultMaxNeighboursForSource,
+		Query:     query,
+		Config:    cfg,
+		NextRunAt: task_config.NextRun(cfg.Schedule, time.Now()),
 	})
 	if err != nil {
 		return 0, err
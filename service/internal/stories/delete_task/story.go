@@ -0,0 +1,42 @@
+package delete_task
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/K1flar/crawlers/internal/business_errors"
+	"github.com/K1flar/crawlers/internal/models/task"
+	"github.com/K1flar/crawlers/internal/storage"
+)
+
+type Story struct {
+	log   *slog.Logger
+	tasks storage.Tasks
+}
+
+func NewStory(
+	log *slog.Logger,
+	tasks storage.Tasks,
+) *Story {
+	return &Story{log, tasks}
+}
+
+// Delete помечает задачу удаленной. Задачу в обработке удаляет только force:
+// обход прерывается так же, как при остановке, а найденные источники удаляются вместе с задачей
+func (s *Story) Delete(ctx context.Context, id int64, force bool) error {
+	t, err := s.tasks.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if t.Status == task.StatusInPocessing {
+		if !force {
+			return business_errors.TaskInProcessing
+		}
+
+		s.log.Info(fmt.Sprintf("cancel processing of deleted task [%d]", id))
+	}
+
+	return s.tasks.Delete(ctx, id)
+}
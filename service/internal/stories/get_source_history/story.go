@@ -0,0 +1,101 @@
+package get_source_history
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/K1flar/crawlers/internal/business_errors"
+	"github.com/K1flar/crawlers/internal/models/source"
+	"github.com/K1flar/crawlers/internal/services/text_diff"
+	"github.com/K1flar/crawlers/internal/storage"
+	"github.com/samber/lo"
+)
+
+type Story struct {
+	sources        storage.Sources
+	sourceContents storage.SourceContents
+}
+
+func NewStory(
+	sources storage.Sources,
+	sourceContents storage.SourceContents,
+) *Story {
+	return &Story{sources, sourceContents}
+}
+
+// Get возвращает историю версий источника и разницу между двумя версиями.
+// По умолчанию сравниваются предпоследняя и последняя версии
+func (s *Story) Get(ctx context.Context, sourceID int64, fromVersion, toVersion *int64) (source.History, error) {
+	versions, err := s.sources.GetVersions(ctx, sourceID)
+	if err != nil {
+		return source.History{}, fmt.Errorf("failed to get versions of source [%d]: %w", sourceID, err)
+	}
+
+	if len(versions) == 0 {
+		return source.History{}, business_errors.EntityNotFound
+	}
+
+	history := source.History{Versions: versions}
+
+	last := versions[len(versions)-1]
+
+	to, ok := findVersion(versions, lo.FromPtrOr(toVersion, last.Version))
+	if !ok {
+		return source.History{}, business_errors.EntityNotFound
+	}
+
+	from, ok := findVersion(versions, lo.FromPtrOr(fromVersion, max(to.Version-1, 1)))
+	if !ok {
+		return source.History{}, business_errors.EntityNotFound
+	}
+
+	history.From, history.To = &from, &to
+
+	if from.Version == to.Version {
+		return history, nil
+	}
+
+	history.TitleDiff = text_diff.Unified(from.Title, to.Title)
+
+	if from.ContentHash == to.ContentHash {
+		return history, nil
+	}
+
+	fromText, err := s.content(ctx, from)
+	if err != nil {
+		return source.History{}, err
+	}
+
+	toText, err := s.content(ctx, to)
+	if err != nil {
+		return source.History{}, err
+	}
+
+	history.ContentDiff = text_diff.Unified(fromText, toText)
+
+	return history, nil
+}
+
+func findVersion(versions []source.Version, number int64) (source.Version, bool) {
+	return lo.Find(versions, func(v source.Version) bool {
+		return v.Version == number
+	})
+}
+
+// content возвращает текст страницы, сохраненный на момент запуска версии
+func (s *Story) content(ctx context.Context, version source.Version) (string, error) {
+	if version.LaunchID == nil {
+		return "", nil
+	}
+
+	content, err := s.sourceContents.Get(ctx, version.SourceID, version.LaunchID)
+	if errors.Is(err, business_errors.EntityNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get content of source [%d] version [%d]: %w", version.SourceID, version.Version, err)
+	}
+
+	return content.Text, nil
+}
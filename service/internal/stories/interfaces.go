@@ -2,16 +2,43 @@ package stories
 
 import (
 	"context"
+
+	"github.com/K1flar/crawlers/internal/models/source"
+	"github.com/K1flar/crawlers/internal/services/task_config"
 )
 
 type CreateTask interface {
-	Create(ctx context.Context, query string) (int64, error)
+	Create(ctx context.Context, query string, patch task_config.Patch) (int64, error)
 }
 
 type ProduceTasksToProcess interface {
-	ProduceAll(ctx context.Context) error
+	ProduceDue(ctx context.Context) error
 }
 
 type ProcessTask interface {
 	Process(ctx context.Context, id int64) error
 }
+
+type CompareLaunches interface {
+	Compare(ctx context.Context, taskID, fromNumber, toNumber int64) (source.Comparison, error)
+}
+
+type DeleteTask interface {
+	Delete(ctx context.Context, id int64, force bool) error
+}
+
+type PurgeDeletedTasks interface {
+	Purge(ctx context.Context) error
+}
+
+type ReindexSourceContents interface {
+	Reindex(ctx context.Context) error
+}
+
+type RekeySources interface {
+	Rekey(ctx context.Context) error
+}
+
+type GetSourceHistory interface {
+	Get(ctx context.Context, sourceID int64, fromVersion, toVersion *int64) (source.History, error)
+}
@@ -2,19 +2,26 @@ package process_task
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sync/atomic"
 	"time"
 
+	"github.com/K1flar/crawlers/internal/business_errors"
 	task_model "github.com/K1flar/crawlers/internal/models/task"
 	"github.com/K1flar/crawlers/internal/services"
 	"github.com/K1flar/crawlers/internal/storage"
 )
 
+// Период проверки, не остановили ли задачу во время обхода
+const stopPollPeriod = 5 * time.Second
+
 type Story struct {
 	log                *slog.Logger
 	tasksStorage       storage.Tasks
 	taskSourcesStorage storage.TaskSources
+	sourcesStorage     storage.Sources
 	launcher           services.Launcher
 	crawler            services.Crawler
 	now                func() time.Time
@@ -24,6 +31,7 @@ func NewStory(
 	log *slog.Logger,
 	tasksStorage storage.Tasks,
 	taskSourcesStorage storage.TaskSources,
+	sourcesStorage storage.Sources,
 	launcher services.Launcher,
 	crawler services.Crawler,
 ) *Story {
@@ -31,6 +39,7 @@ func NewStory(
 		log:                log,
 		tasksStorage:       tasksStorage,
 		taskSourcesStorage: taskSourcesStorage,
+		sourcesStorage:     sourcesStorage,
 		launcher:           launcher,
 		crawler:            crawler,
 		now:                time.Now,
@@ -49,6 +58,12 @@ func (s *Story) Process(ctx context.Context, id int64) error {
 		return fmt.Errorf("task [%d] is not in created or active status (%s)", task.ID, task.Status)
 	}
 
+	// Источники прошлого запуска загружаются условными запросами
+	known, err := s.sourcesStorage.GetKnownByTaskID(ctx, id)
+	if err != nil {
+		return err
+	}
+
 	err = s.tasksStorage.Process(ctx, id)
 	if err != nil {
 		return err
@@ -60,24 +75,90 @@ func (s *Story) Process(ctx context.Context, id int64) error {
 	}
 	s.log.Info(fmt.Sprintf("new launch with id [%d]", launchID))
 
-	pages, crawlerErr := s.crawler.Start(ctx, task)
+	crawlCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var cancelled atomic.Bool
+	go s.watchStop(crawlCtx, id, func() {
+		cancelled.Store(true)
+		cancel()
+	})
+
+	pages, skipped, crawlerErr := s.crawler.Start(crawlCtx, task, known)
+	cancel()
+
+	if cancelled.Load() {
+		s.log.Info(fmt.Sprintf("task [%d] stopped during crawling, save %d pages", id, len(pages)))
+		crawlerErr = nil
+	}
 
 	newStatus := task_model.StatusActive
 	if crawlerErr != nil {
 		newStatus = task_model.StatusStoppedWithError
 	}
 
-	err = s.launcher.Finish(ctx, services.LaunhToFinishParams{
-		LaunchID: launchID,
-		Task:     task,
-		Pages:    pages,
-		Error:    crawlerErr,
+	finishErr := s.launcher.Finish(ctx, services.LaunhToFinishParams{
+		LaunchID:  launchID,
+		Task:      task,
+		Pages:     pages,
+		Known:     known,
+		Skipped:   skipped,
+		Error:     crawlerErr,
+		Cancelled: cancelled.Load(),
 	})
 
-	err = s.tasksStorage.SetStatus(ctx, id, newStatus)
+	// Остановленная задача остается в статусе stopped
+	updated, err := s.tasksStorage.FinishProcessing(ctx, id, newStatus)
 	if err != nil {
 		return err
 	}
 
-	return err
+	if !updated {
+		s.log.Info(fmt.Sprintf("task [%d] status changed during processing, keep it", id))
+	}
+
+	if finishErr != nil {
+		return finishErr
+	}
+
+	// Пересказ не влияет на результат запуска
+	if crawlerErr == nil {
+		if err := s.launcher.Summarize(ctx, launchID); err != nil {
+			s.log.Warn(fmt.Sprintf("failed to summarize launch [%d]: %s", launchID, err))
+		}
+	}
+
+	return nil
+}
+
+// watchStop опрашивает статус задачи и вызывает stop, если задачу остановили
+func (s *Story) watchStop(ctx context.Context, id int64, stop func()) {
+	ticker := time.NewTicker(stopPollPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		task, err := s.tasksStorage.GetByID(ctx, id)
+		// Удаленная задача тоже останавливается
+		if errors.Is(err, business_errors.EntityNotFound) {
+			stop()
+			return
+		}
+		if err != nil {
+			if ctx.Err() == nil {
+				s.log.Warn(fmt.Sprintf("failed to check status of task [%d]: %s", id, err))
+			}
+			continue
+		}
+
+		if task.Status == task_model.StatusStopped {
+			stop()
+			return
+		}
+	}
 }
@@ -2,11 +2,15 @@ package produce_tasks_to_process
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/K1flar/crawlers/internal/message_broker"
 	"github.com/K1flar/crawlers/internal/message_broker/messages"
 	"github.com/K1flar/crawlers/internal/models/task"
+	"github.com/K1flar/crawlers/internal/services/task_config"
 	"github.com/K1flar/crawlers/internal/storage"
+	"github.com/samber/lo"
 )
 
 type Story struct {
@@ -24,20 +28,37 @@ func NewStory(
 	}
 }
 
-func (s *Story) ProduceAll(ctx context.Context) error {
-	tasks, err := s.storage.FindInStatuses(ctx, []task.Status{task.StatusActive})
-	if err != nil {
-		return err
-	}
+// ProduceDue ставит в очередь активные задачи, время запуска которых наступило,
+// и планирует их следующий запуск по расписанию задачи
+func (s *Story) ProduceDue(ctx context.Context) error {
+	for {
+		now := time.Now()
+
+		var nextRunAt time.Time
 
-	for _, task := range tasks {
-		err := s.producer.Produce(ctx, messages.TaskToProcessMessage{
-			ID: task.ID,
+		// Блокировка задачи не удерживается на время записи в брокер
+		t, ok, err := s.storage.ClaimDue(ctx, now, func(t task.Task) time.Time {
+			nextRunAt = task_config.NextRun(t.Schedule, now)
+			return nextRunAt
 		})
 		if err != nil {
 			return err
 		}
-	}
 
-	return nil
+		if !ok {
+			return nil
+		}
+
+		err = s.producer.Produce(ctx, messages.TaskToProcessMessage{
+			ID: t.ID,
+		})
+		if err != nil {
+			// Задача остается к запуску и будет поставлена в очередь на следующем проходе
+			if releaseErr := s.storage.ReleaseDue(ctx, t.ID, nextRunAt, lo.FromPtr(t.NextRunAt)); releaseErr != nil {
+				return fmt.Errorf("failed to produce task [%d]: %w; failed to release it: %w", t.ID, err, releaseErr)
+			}
+
+			return fmt.Errorf("failed to produce task [%d]: %w", t.ID, err)
+		}
+	}
 }
@@ -0,0 +1,113 @@
+package produce_tasks_to_process
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/K1flar/crawlers/internal/message_broker/messages"
+	"github.com/K1flar/crawlers/internal/models/task"
+	"github.com/K1flar/crawlers/internal/storage"
+)
+
+// tasksStub хранит время следующего запуска задач и журнал вызовов
+type tasksStub struct {
+	storage.Tasks
+
+	tasks []task.Task
+	log   *[]string
+}
+
+func (s *tasksStub) ClaimDue(_ context.Context, now time.Time, next func(t task.Task) time.Time) (task.Task, bool, error) {
+	for i, t := range s.tasks {
+		if t.NextRunAt.After(now) {
+			continue
+		}
+
+		nextRunAt := next(t)
+		s.tasks[i].NextRunAt = &nextRunAt
+		*s.log = append(*s.log, "claim")
+
+		return t, true, nil
+	}
+
+	return task.Task{}, false, nil
+}
+
+func (s *tasksStub) ReleaseDue(_ context.Context, id int64, claimedNextRunAt, dueAt time.Time) error {
+	*s.log = append(*s.log, "release")
+
+	for i, t := range s.tasks {
+		if t.ID == id && t.NextRunAt.Equal(claimedNextRunAt) {
+			s.tasks[i].NextRunAt = &dueAt
+		}
+	}
+
+	return nil
+}
+
+type producerStub struct {
+	err error
+	log *[]string
+}
+
+func (p *producerStub) Produce(context.Context, messages.TaskToProcessMessage) error {
+	*p.log = append(*p.log, "produce")
+
+	return p.err
+}
+
+func TestProduceDue(t *testing.T) {
+	due := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name       string
+		produceErr error
+		wantLog    []string
+		wantDue    bool
+	}{
+		{
+			name:    "claims before producing",
+			wantLog: []string{"claim", "produce"},
+		},
+		{
+			name:       "releases task when producer fails",
+			produceErr: errors.New("broker is down"),
+			wantLog:    []string{"claim", "produce", "release"},
+			wantDue:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var log []string
+
+			tasks := &tasksStub{
+				tasks: []task.Task{{ID: 1, Config: task.Config{Schedule: "@every 1h"}, NextRunAt: &due}},
+				log:   &log,
+			}
+
+			story := NewStory(tasks, &producerStub{err: tt.produceErr, log: &log})
+
+			err := story.ProduceDue(context.Background())
+			if (err != nil) != (tt.produceErr != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(log) != len(tt.wantLog) {
+				t.Fatalf("got calls %v, want %v", log, tt.wantLog)
+			}
+
+			for i := range log {
+				if log[i] != tt.wantLog[i] {
+					t.Fatalf("got calls %v, want %v", log, tt.wantLog)
+				}
+			}
+
+			if got := tasks.tasks[0].NextRunAt.Equal(due); got != tt.wantDue {
+				t.Fatalf("task due again: got %v, want %v", got, tt.wantDue)
+			}
+		})
+	}
+}
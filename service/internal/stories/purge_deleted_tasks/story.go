@@ -0,0 +1,55 @@
+package purge_deleted_tasks
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/K1flar/crawlers/internal/storage"
+)
+
+// Источник, не привязанный к задаче, может принадлежать идущему запуску
+const orphanGracePeriod = time.Hour
+
+type Story struct {
+	log       *slog.Logger
+	tasks     storage.Tasks
+	sources   storage.Sources
+	retention time.Duration
+	now       func() time.Time
+}
+
+func NewStory(
+	log *slog.Logger,
+	tasks storage.Tasks,
+	sources storage.Sources,
+	retention time.Duration,
+) *Story {
+	return &Story{
+		log:       log,
+		tasks:     tasks,
+		sources:   sources,
+		retention: retention,
+		now:       time.Now,
+	}
+}
+
+// Purge окончательно удаляет задачи, удаленные раньше срока хранения, и источники, которые больше не нужны ни одной задаче
+func (s *Story) Purge(ctx context.Context) error {
+	now := s.now()
+
+	tasks, err := s.tasks.PurgeDeleted(ctx, now.Add(-s.retention))
+	if err != nil {
+		return fmt.Errorf("failed to purge deleted tasks: %w", err)
+	}
+
+	sources, err := s.sources.DeleteOrphaned(ctx, now.Add(-orphanGracePeriod))
+	if err != nil {
+		return fmt.Errorf("failed to delete orphaned sources: %w", err)
+	}
+
+	s.log.Info(fmt.Sprintf("purge %d deleted tasks and %d orphaned sources", tasks, sources))
+
+	return nil
+}
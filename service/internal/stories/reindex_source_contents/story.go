@@ -0,0 +1,48 @@
+package reindex_source_contents
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/K1flar/crawlers/internal/storage"
+)
+
+const batchSize = 500
+
+type Story struct {
+	log            *slog.Logger
+	sourceContents storage.SourceContents
+}
+
+func NewStory(
+	log *slog.Logger,
+	sourceContents storage.SourceContents,
+) *Story {
+	return &Story{
+		log:            log,
+		sourceContents: sourceContents,
+	}
+}
+
+// Reindex добавляет в поисковый индекс текст записей, сохраненных до появления поиска
+func (s *Story) Reindex(ctx context.Context) error {
+	total := 0
+
+	for {
+		count, err := s.sourceContents.Reindex(ctx, batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to reindex source contents: %w", err)
+		}
+
+		total += count
+
+		if count < batchSize {
+			break
+		}
+	}
+
+	s.log.Info(fmt.Sprintf("reindex %d source contents", total))
+
+	return nil
+}
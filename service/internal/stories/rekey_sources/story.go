@@ -0,0 +1,48 @@
+package rekey_sources
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/K1flar/crawlers/internal/storage"
+)
+
+const batchSize = 500
+
+type Story struct {
+	log     *slog.Logger
+	sources storage.Sources
+}
+
+func NewStory(
+	log *slog.Logger,
+	sources storage.Sources,
+) *Story {
+	return &Story{
+		log:     log,
+		sources: sources,
+	}
+}
+
+// Rekey заполняет ключи адресов источников, сохраненных до нормализации URL, и объединяет их дубли
+func (s *Story) Rekey(ctx context.Context) error {
+	total := 0
+
+	for {
+		count, err := s.sources.Rekey(ctx, batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to rekey sources: %w", err)
+		}
+
+		total += count
+
+		if count < batchSize {
+			break
+		}
+	}
+
+	s.log.Info(fmt.Sprintf("rekey %d sources", total))
+
+	return nil
+}